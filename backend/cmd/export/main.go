@@ -0,0 +1,76 @@
+// Command export writes a repo's GitSense data as CSV or NDJSON, the same
+// datasets as the /export endpoints:
+//
+//	go run ./cmd/export -repo name -dataset commits -format csv -from 90d -o commits.csv
+//
+// The database is located like the server's (DB_PATH, default gitsense.db).
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"gitsense"
+	"gitsense/internal/bots"
+	"gitsense/internal/db"
+	"gitsense/internal/export"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	repo := flag.String("repo", "", "repository name (required)")
+	dataset := flag.String("dataset", "commits", "dataset to export: "+strings.Join(export.Datasets(), ", "))
+	format := flag.String("format", export.FormatCSV, "output format: csv or ndjson")
+	from := flag.String("from", "", "start of the range: YYYY-MM-DD, RFC3339 or relative (90d, 12w, 6m, 1y)")
+	to := flag.String("to", "", "end of the range, same formats as -from (default now)")
+	includeBots := flag.String("include-bots", "", "true or false to override the repo's bot setting")
+	output := flag.String("o", "", "output file (default stdout)")
+	flag.Parse()
+
+	if *repo == "" {
+		fail(fmt.Errorf("-repo is required"))
+	}
+	if err := export.Validate(*dataset, *format); err != nil {
+		fail(err)
+	}
+	tr, err := gitsense.ParseTimeRange(*from, *to)
+	if err != nil {
+		fail(err)
+	}
+
+	// Same environment as the server, for DB_PATH
+	godotenv.Load("../.env")
+	if err := db.InitDB(); err != nil {
+		fail(err)
+	}
+
+	detector, err := bots.ForParam(*repo, *includeBots)
+	if err != nil {
+		fail(err)
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fail(err)
+		}
+		defer f.Close()
+		out = f
+	}
+
+	n, err := export.Write(out, *dataset, *format, *repo, tr, detector)
+	if err != nil {
+		fail(err)
+	}
+	fmt.Fprintf(os.Stderr, "✅ Exported %d %s rows for %s\n", n, *dataset, *repo)
+}
+
+func fail(err error) {
+	fmt.Fprintf(os.Stderr, "❌ %v\n", err)
+	os.Exit(1)
+}
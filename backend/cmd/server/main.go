@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	_ "time/tzdata" // IANA zones for the tz parameter, even without system tzdata
 
 	"gitsense"
 	"gitsense/internal/api"
@@ -44,6 +45,8 @@ func main() {
 
 	// Project data
 	http.HandleFunc("/project/summary", api.GetProjectSummary)
+	http.HandleFunc("/project/portfolio", api.GetPortfolioSummary)
+	http.HandleFunc("/portfolio", api.GetPortfolioDashboard)
 	http.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
 
 	// 🔐 OAuth routes
@@ -76,14 +79,44 @@ func main() {
 
 	http.HandleFunc("/repos", repos.GetUserRepos)
 	http.HandleFunc("/history", api.GetRepoHistory)
+	http.HandleFunc("/compare", api.GetRepoComparison)
+	http.HandleFunc("/forecast", api.GetActivityForecast)
 	http.HandleFunc("/commits", commits.GetCommits)
+	http.HandleFunc("/commits/detail", commits.GetCommitDetail)
 	http.HandleFunc("/files", api.GetFileActivity)
+	http.HandleFunc("/files/tree", api.GetFileTree)
+	http.HandleFunc("/files/history", api.GetFileHistory)
 	http.HandleFunc("/dashboard", api.DashboardHandler)
 
 	// New analytics endpoints
 	http.HandleFunc("/commits-per-day", api.GetCommitsPerDay)
+	http.HandleFunc("/commit-heatmap", api.GetCommitHeatmap)
 	http.HandleFunc("/file-breakdown", api.GetFileBreakdown)
 	http.HandleFunc("/contributor-distribution", api.GetContributorDistribution)
+	http.HandleFunc("/contributors/profile", api.GetContributorProfile)
+	http.HandleFunc("/contributors/cohorts", api.GetContributorCohorts)
+	http.HandleFunc("/languages", api.GetLanguageBreakdown)
+	http.HandleFunc("/churn/daily", api.GetChurnPerDay)
+	http.HandleFunc("/churn/files", api.GetChurnByFile)
+	http.HandleFunc("/churn/authors", api.GetChurnByAuthor)
+	http.HandleFunc("/churn/comparison", api.GetChurnComparison)
+	http.HandleFunc("/hotspots", api.GetHotspots)
+	http.HandleFunc("/ownership", api.GetOwnership)
+	http.HandleFunc("/ownership/breakdown", api.GetOwnershipBreakdown)
+	http.HandleFunc("/ownership/single-owner", api.GetSingleOwnerFiles)
+	http.HandleFunc("/coupling", api.GetChangeCoupling)
+	http.HandleFunc("/coupling/graph", api.GetChangeCouplingGraph)
+	http.HandleFunc("/commit-types", api.GetCommitTypes)
+	http.HandleFunc("/commit-types/contributors", api.GetCommitTypesByContributor)
+	http.HandleFunc("/anomalies", api.GetAnomalies)
+	http.HandleFunc("/search", api.GetCommitSearch)
+	http.HandleFunc("/export/", api.ExportHandler)
+
+	// Per-repo settings
+	http.HandleFunc("/settings", api.RepoSettingsHandler)
+	http.HandleFunc("/score-models", api.GetScoreModels)
+	http.HandleFunc("/ignore-rules", api.IgnoreRulesHandler)
+	http.HandleFunc("/ignore-rules/preview", api.PreviewIgnoreRules)
 
 	port := os.Getenv("PORT")
 	if port == "" {
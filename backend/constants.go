@@ -8,6 +8,13 @@ const (
 	DefaultCommitLimit = 30
 	MaxCommitLimit     = 100
 
+	// Shortest SHA prefix accepted by the commit detail endpoint
+	MinSHAPrefix = 7
+
+	// Page size of /files
+	DefaultFileLimit = 100
+	MaxFileLimit     = 1000
+
 	// HTTP Client timeouts
 	GitHubAPITimeout = 30 * time.Second
 	DefaultTimeout   = 10 * time.Second
@@ -16,19 +23,108 @@ const (
 	MaxDBRetries      = 5
 	InitialRetryDelay = 100 * time.Millisecond
 
-	// Activity thresholds (in days)
-	ActiveThreshold   = 7
-	StableThreshold   = 30
-	InactiveThreshold = 30
+	// Default activity thresholds (in days), overridable per repo
+	ActiveThreshold  = 7
+	StableThreshold  = 30
+	MaxThresholdDays = 3650
+
+	// Maximum number of repo-specific bot author names
+	MaxBotAuthors = 100
 
 	// Server timeouts
 	ServerReadTimeout  = 15 * time.Second
 	ServerWriteTimeout = 15 * time.Second
 	ServerIdleTimeout  = 60 * time.Second
 
+	// Directory tree rollups
+	DefaultTreeDepth    = 1
+	MaxTreeDepth        = 10
+	TreeTopContributors = 3
+
+	// Churn file/author listings
+	DefaultChurnLimit = 50
+	MaxChurnLimit     = 1000
+
+	// Hotspot analysis
+	DefaultHotspotDays  = 90
+	DefaultHotspotLimit = 20
+	MaxHotspotLimit     = 200
+
+	// Bus factor: authors needed to cover more than this share of the work
+	BusFactorShare = 0.5
+
+	// Change coupling analysis
+	DefaultCouplingDays          = 180
+	DefaultCouplingMinSupport    = 3
+	DefaultCouplingMinConfidence = 0.5
+	MaxCouplingCommitFiles       = 50
+
+	// Anomaly detection
+	SpikeBaselineDays     = 28
+	SpikeMADFactor        = 3.0
+	SpikeMinCommits       = 5
+	DroughtBaselineWeeks  = 8
+	DroughtDropRatio      = 0.8
+	DroughtMinBaseline    = 5
+	DroughtMinElapsedDays = 3
+	SilentMinCommits      = 5
+	SilentMinDays         = 14
+	SilentGapFactor       = 4.0
+	DefaultAnomalyLimit   = 50
+	MaxAnomalyLimit       = 500
+
+	// Activity forecasting
+	DefaultForecastDays = 14
+	MaxForecastDays     = 90
+	MinForecastPoints   = 3
+
+	// Contributor profiles: number of top files/directories listed
+	ProfileTopPaths = 10
+
+	// File history: how many earlier names a renamed file is followed through
+	MaxFileRenames = 20
+
+	// Health scoring: composite model window, saturation scales (the
+	// value that scores ~63/100) and component weights
+	CompositeWindowDays      = 28
+	CompositeVelocityScale   = 5.0   // commits per week
+	CompositeChurnScale      = 500.0 // lines changed per week
+	CompositeDiversityTarget = 4.0   // effective contributors for a full score
+	CompositeVelocityWeight  = 0.30
+	CompositeDiversityWeight = 0.25
+	CompositeChurnWeight     = 0.20
+	CompositeCIWeight        = 0.25
+	MaxCIRunsPerSync         = 100
+
+	// Repo comparison
+	DefaultCompareDays = 90
+	MaxCompareRepos    = 5
+
+	// Portfolio aggregates: velocity window, contributor window and list
+	// size, the score change over PortfolioTrendDays that counts as a trend,
+	// and how old a row may get before it is recomputed on read
+	PortfolioVelocityDays    = 28
+	PortfolioContributorDays = 90
+	PortfolioTopContributors = 3
+	PortfolioTrendDays       = 7
+	PortfolioTrendMinChange  = 2.0
+	PortfolioStaleHours      = 6
+
+	// Bulk export: rows written between flushes to the client
+	ExportFlushRows = 500
+
+	// Commit search: page size and tokens of context in message snippets
+	DefaultSearchLimit  = 20
+	MaxSearchLimit      = 100
+	SearchSnippetTokens = 16
+
 	// Historical snapshot window
 	HistoricalSnapshotDays = 30
 
+	// Ignore rule limits
+	MaxRepoConfigBytes = 64 * 1024
+	MaxIgnorePatterns  = 200
+
 	// Session configuration
 	SessionTTLHours = 24 * 30
 )
@@ -1,9 +1,12 @@
 package gitsense
 
 import (
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -31,7 +34,8 @@ func SendErrorResponse(w http.ResponseWriter, message string, statusCode int) {
 func SendJSONError(w http.ResponseWriter, message string, statusCode int) {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
-	fmt.Fprintf(w, `{"error": "%s"}`, message)
+	// Encoded rather than formatted: messages can echo user input
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
 }
 
 // validateRepoParam validates the repo query parameter
@@ -69,19 +73,127 @@ func ValidateLimitParam(r *http.Request) (int, error) {
 	return limit, nil
 }
 
-// isFileActive determines if a file is active based on days since last modification
-func IsFileActive(daysSinceModified float64) bool {
-	return daysSinceModified <= float64(ActiveThreshold)
+// ValidateIntParam validates an optional integer query parameter within [min, max]
+func ValidateIntParam(r *http.Request, name string, defaultValue, min, max int) (int, error) {
+	valueStr := r.URL.Query().Get(name)
+	if valueStr == "" {
+		return defaultValue, nil
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s parameter: must be a number", name)
+	}
+
+	if value < min {
+		return 0, fmt.Errorf("%s must be at least %d", name, min)
+	}
+
+	if value > max {
+		return 0, fmt.Errorf("%s exceeds maximum of %d", name, max)
+	}
+
+	return value, nil
 }
 
-// isFileStable determines if a file is stable based on days since last modification
-func IsFileStable(daysSinceModified float64) bool {
-	return daysSinceModified > float64(ActiveThreshold) && daysSinceModified <= float64(StableThreshold)
+// ValidateTimezoneParam parses the optional tz query parameter, accepting an
+// IANA zone name ("Europe/Berlin"), "UTC" or a fixed offset ("+05:30", "-0800").
+// It returns nil when the parameter is absent.
+func ValidateTimezoneParam(r *http.Request) (*time.Location, error) {
+	tz := r.URL.Query().Get("tz")
+	// An unescaped + in a query string decodes to a space
+	if strings.HasPrefix(tz, " ") {
+		tz = "+" + strings.TrimLeft(tz, " ")
+	}
+	tz = strings.TrimSpace(tz)
+	if tz == "" || tz == "+" {
+		return nil, nil
+	}
+
+	if tz[0] == '+' || tz[0] == '-' {
+		digits := strings.Replace(tz[1:], ":", "", 1)
+		if len(digits) != 4 {
+			return nil, fmt.Errorf("invalid tz offset: use +HH:MM or -HH:MM")
+		}
+		hours, errH := strconv.Atoi(digits[:2])
+		minutes, errM := strconv.Atoi(digits[2:])
+		if errH != nil || errM != nil || hours > 14 || minutes > 59 {
+			return nil, fmt.Errorf("invalid tz offset: use +HH:MM or -HH:MM")
+		}
+		offset := hours*3600 + minutes*60
+		if tz[0] == '-' {
+			offset = -offset
+		}
+		return time.FixedZone(tz, offset), nil
+	}
+
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("unknown tz: %s", tz)
+	}
+	return loc, nil
 }
 
-// isFileInactive determines if a file is inactive based on days since last modification
-func IsFileInactive(daysSinceModified float64) bool {
-	return daysSinceModified > float64(InactiveThreshold)
+// ValidateAsOfParam parses the optional as_of query parameter as a date
+// (YYYY-MM-DD, meaning the end of that UTC day) or an RFC3339 timestamp.
+// It returns the zero time when the parameter is absent.
+func ValidateAsOfParam(r *http.Request) (time.Time, error) {
+	value := strings.TrimSpace(r.URL.Query().Get("as_of"))
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	asOf, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		day, dayErr := time.Parse("2006-01-02", value)
+		if dayErr != nil {
+			return time.Time{}, fmt.Errorf("invalid as_of: use YYYY-MM-DD or RFC3339")
+		}
+		asOf = day.Add(24*time.Hour - time.Second)
+	}
+
+	if asOf.After(time.Now()) {
+		return time.Time{}, fmt.Errorf("as_of cannot be in the future")
+	}
+	return asOf.UTC(), nil
+}
+
+// EncodeCursor packs the sort key of the last item on a page into an opaque
+// pagination cursor
+func EncodeCursor(key ...string) string {
+	data, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ValidateCursorParam decodes the optional cursor query parameter into a
+// sort key of n parts. It returns nil when the parameter is absent.
+func ValidateCursorParam(r *http.Request, n int) ([]string, error) {
+	value := r.URL.Query().Get("cursor")
+	if value == "" {
+		return nil, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	var key []string
+	if err := json.Unmarshal(data, &key); err != nil || len(key) != n {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+	return key, nil
+}
+
+// Round rounds v half away from zero to the given number of decimal places
+func Round(v float64, places int) float64 {
+	scale := 1.0
+	for i := 0; i < places; i++ {
+		scale *= 10
+	}
+	if v < 0 {
+		return -float64(int(-v*scale+0.5)) / scale
+	}
+	return float64(int(v*scale+0.5)) / scale
 }
 
 // setCORSHeaders sets CORS headers for cross-origin requests
@@ -0,0 +1,151 @@
+package activity
+
+import (
+	"fmt"
+
+	"gitsense"
+	"gitsense/internal/bots"
+	"gitsense/internal/db"
+	"gitsense/internal/ignore"
+)
+
+// FileStat is a tracked file's commit count and last modification date
+type FileStat struct {
+	Name         string
+	CommitCount  int
+	LastModified string
+	SizeBytes    int64
+}
+
+// LoadFileStats returns a repo's file activity with ignored paths removed.
+//
+// With a bot detector, counts and dates are rebuilt from the per-commit file
+// lists without bot commits, and files only bots have touched are dropped.
+// Files synced before per-commit data was recorded keep their stored values.
+func LoadFileStats(repo string, detector *bots.Detector) ([]FileStat, error) {
+	matcher, err := ignore.ForRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT file_name, commit_count, last_modified, size_bytes
+		FROM file_activity
+		WHERE repo_name = ?
+	`, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load file activity: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []FileStat
+	for rows.Next() {
+		var f FileStat
+		if err := rows.Scan(&f.Name, &f.CommitCount, &f.LastModified, &f.SizeBytes); err != nil {
+			return nil, fmt.Errorf("failed to scan file activity: %w", err)
+		}
+		if matcher.Ignored(f.Name) {
+			continue
+		}
+		stats = append(stats, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	if detector == nil {
+		return stats, nil
+	}
+	return rebuild(repo, stats, detector, nil)
+}
+
+// LoadFileStatsInRange returns a repo's file activity as it stood at the end
+// of the range: every file changed by then, dated by its last change up to
+// that point, with commit counts taken only from commits within the range
+// (zero for files untouched in it). Files without per-commit data keep
+// their stored values if their last modification is no later than the end,
+// counted only when it falls within the range.
+func LoadFileStatsInRange(repo string, detector *bots.Detector, tr gitsense.TimeRange) ([]FileStat, error) {
+	stats, err := LoadFileStats(repo, nil)
+	if err != nil {
+		return nil, err
+	}
+	return rebuild(repo, stats, detector, &tr)
+}
+
+// rebuild recomputes counts and dates from the per-commit file lists,
+// skipping commits the detector excludes. When tr is set, commits after its
+// end are skipped and only those within it are counted.
+func rebuild(repo string, stats []FileStat, detector *bots.Detector, tr *gitsense.TimeRange) ([]FileStat, error) {
+	inRange := func(date string) bool {
+		return tr == nil || tr.ContainsTimestamp(date)
+	}
+	byEnd := func(date string) bool {
+		return tr == nil || gitsense.TimeRange{To: tr.To}.ContainsTimestamp(date)
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT cf.file_name, c.author, c.commit_date
+		FROM commit_files cf
+		JOIN commits c ON c.commit_sha = cf.commit_sha
+		WHERE cf.repo_name = ?
+	`, repo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load commit files: %w", err)
+	}
+	defer rows.Close()
+
+	type humanActivity struct {
+		count        int
+		lastModified string
+	}
+	recorded := map[string]bool{}
+	human := map[string]*humanActivity{}
+
+	for rows.Next() {
+		var fileName, author, commitDate string
+		if err := rows.Scan(&fileName, &author, &commitDate); err != nil {
+			return nil, fmt.Errorf("failed to scan commit files: %w", err)
+		}
+		recorded[fileName] = true
+		if detector.Excludes(author) || !byEnd(commitDate) {
+			continue
+		}
+		h := human[fileName]
+		if h == nil {
+			h = &humanActivity{}
+			human[fileName] = h
+		}
+		if inRange(commitDate) {
+			h.count++
+		}
+		if commitDate > h.lastModified {
+			h.lastModified = commitDate
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	filtered := stats[:0]
+	for _, f := range stats {
+		if !recorded[f.Name] {
+			if byEnd(f.LastModified) {
+				if !inRange(f.LastModified) {
+					f.CommitCount = 0
+				}
+				filtered = append(filtered, f)
+			}
+			continue
+		}
+		h := human[f.Name]
+		if h == nil {
+			continue
+		}
+		f.CommitCount = h.count
+		f.LastModified = h.lastModified
+		filtered = append(filtered, f)
+	}
+	return filtered, nil
+}
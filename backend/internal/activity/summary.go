@@ -0,0 +1,77 @@
+package activity
+
+import (
+	"time"
+
+	"gitsense"
+	"gitsense/internal/bots"
+	"gitsense/internal/models"
+	"gitsense/internal/scoring"
+	"gitsense/internal/settings"
+)
+
+// Summarize classifies a repo's files against its thresholds and scores them,
+// either now or, given a range, as of its end; every file changed by then is
+// classified, whether or not it was changed within the range.
+// A nil model means the repo's configured one.
+func Summarize(repo string, detector *bots.Detector, tr *gitsense.TimeRange, model scoring.Model) (models.ProjectSummary, error) {
+	summary := models.ProjectSummary{Repo: repo}
+
+	thresholds, err := settings.GetThresholds(repo)
+	if err != nil {
+		return summary, err
+	}
+
+	reference := time.Now().UTC()
+	var files []FileStat
+	if tr == nil {
+		files, err = LoadFileStats(repo, detector)
+	} else {
+		reference = tr.To
+		files, err = LoadFileStatsInRange(repo, detector, *tr)
+	}
+	if err != nil {
+		return summary, err
+	}
+
+	var ages []float64
+	for _, f := range files {
+		t, err := time.Parse(time.RFC3339, f.LastModified)
+		if err != nil {
+			continue
+		}
+
+		age := reference.Sub(t).Hours() / 24
+		ages = append(ages, age)
+		summary.TotalFiles++
+
+		switch thresholds.Classify(age) {
+		case "active":
+			summary.ActiveFiles++
+		case "stable":
+			summary.StableFiles++
+		default:
+			summary.InactiveFiles++
+		}
+	}
+
+	if model == nil {
+		model = scoring.ForRepo(repo)
+	}
+	result, err := scoring.Evaluate(model, scoring.Input{
+		Repo:       repo,
+		Detector:   detector,
+		At:         reference,
+		Thresholds: thresholds,
+		FileAges:   ages,
+	})
+	if err != nil {
+		return summary, err
+	}
+
+	summary.ActivityScore = result.Score
+	summary.ProjectState = result.State
+	summary.ScoreModel = result.Model
+	summary.ScoreComponents = result.Components
+	return summary, nil
+}
@@ -0,0 +1,359 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"gitsense"
+	"gitsense/internal/bots"
+	"gitsense/internal/db"
+)
+
+// Event kinds
+const (
+	KindSpike           = "spike"
+	KindDrought         = "drought"
+	KindContributorGone = "contributor_silent"
+)
+
+// Event is an unusual period of commit activity
+type Event struct {
+	Kind        string  `json:"kind"`
+	Subject     string  `json:"subject,omitempty"`
+	PeriodStart string  `json:"period_start"`
+	PeriodEnd   string  `json:"period_end"`
+	Observed    float64 `json:"observed"`
+	Expected    float64 `json:"expected"`
+	Message     string  `json:"message"`
+	DetectedAt  string  `json:"detected_at,omitempty"`
+}
+
+// Commit is the minimal commit data the detectors need
+type Commit struct {
+	Author string
+	Date   time.Time
+}
+
+const dayFormat = "2006-01-02"
+
+// Detect runs every detector over a repo's commits as of now
+func Detect(commits []Commit, now time.Time) []Event {
+	var events []Event
+	events = append(events, detectSpikes(commits, now)...)
+	events = append(events, detectDroughts(commits, now)...)
+	events = append(events, detectSilentContributors(commits, now)...)
+	return events
+}
+
+// detectSpikes flags days whose commit count exceeds the rolling median of
+// the previous SpikeBaselineDays by SpikeMADFactor robust standard deviations
+func detectSpikes(commits []Commit, now time.Time) []Event {
+	if len(commits) == 0 {
+		return nil
+	}
+
+	perDay := map[string]int{}
+	first := now
+	for _, c := range commits {
+		d := truncateDay(c.Date)
+		perDay[d.Format(dayFormat)]++
+		if d.Before(first) {
+			first = d
+		}
+	}
+
+	var days []float64
+	var events []Event
+	for d := first; !d.After(now); d = d.AddDate(0, 0, 1) {
+		key := d.Format(dayFormat)
+		count := float64(perDay[key])
+
+		if len(days) >= gitsense.SpikeBaselineDays {
+			baseline := days[len(days)-gitsense.SpikeBaselineDays:]
+			median, mad := medianMAD(baseline)
+			spread := math.Max(1.4826*mad, 1)
+
+			if count >= gitsense.SpikeMinCommits && count > median+gitsense.SpikeMADFactor*spread {
+				events = append(events, Event{
+					Kind:        KindSpike,
+					PeriodStart: key,
+					PeriodEnd:   key,
+					Observed:    count,
+					Expected:    median,
+					Message:     fmt.Sprintf("%d commits on %s, typical day has %.0f", int(count), key, median),
+				})
+			}
+		}
+		days = append(days, count)
+	}
+	return events
+}
+
+// detectDroughts flags calendar weeks whose commits dropped by at least
+// DroughtDropRatio against the median of the previous DroughtBaselineWeeks.
+// The current, partial week is compared against a pro-rated baseline.
+func detectDroughts(commits []Commit, now time.Time) []Event {
+	if len(commits) == 0 {
+		return nil
+	}
+
+	perWeek := map[string]int{}
+	first := weekStart(now)
+	for _, c := range commits {
+		w := weekStart(c.Date)
+		perWeek[w.Format(dayFormat)]++
+		if w.Before(first) {
+			first = w
+		}
+	}
+
+	var weeks []float64
+	var events []Event
+	current := weekStart(now)
+	for w := first; !w.After(current); w = w.AddDate(0, 0, 7) {
+		key := w.Format(dayFormat)
+		count := float64(perWeek[key])
+
+		if len(weeks) >= gitsense.DroughtBaselineWeeks {
+			median, _ := medianMAD(weeks[len(weeks)-gitsense.DroughtBaselineWeeks:])
+			expected := median
+			end := w.AddDate(0, 0, 6)
+
+			evaluate := true
+			if w.Equal(current) {
+				elapsed := int(truncateDay(now).Sub(w).Hours()/24) + 1
+				evaluate = elapsed >= gitsense.DroughtMinElapsedDays
+				expected = median * float64(elapsed) / 7
+				end = truncateDay(now)
+			}
+
+			if evaluate && median >= gitsense.DroughtMinBaseline && count <= expected*(1-gitsense.DroughtDropRatio) {
+				drop := 100 * (1 - count/expected)
+				events = append(events, Event{
+					Kind:        KindDrought,
+					PeriodStart: key,
+					PeriodEnd:   end.Format(dayFormat),
+					Observed:    count,
+					Expected:    round1(expected),
+					Message:     fmt.Sprintf("Commits dropped %.0f%% in the week of %s (%d vs. typical %.0f)", drop, key, int(count), expected),
+				})
+			}
+		}
+		weeks = append(weeks, count)
+	}
+	return events
+}
+
+// detectSilentContributors flags regular contributors whose last commit is
+// both older than SilentMinDays and far longer ago than their usual gap
+func detectSilentContributors(commits []Commit, now time.Time) []Event {
+	byAuthor := map[string][]time.Time{}
+	for _, c := range commits {
+		byAuthor[c.Author] = append(byAuthor[c.Author], c.Date)
+	}
+
+	var events []Event
+	for author, dates := range byAuthor {
+		sort.Slice(dates, func(i, j int) bool { return dates[i].Before(dates[j]) })
+		last := dates[len(dates)-1]
+
+		// Only contributors who were regular before going quiet
+		windowStart := last.AddDate(0, 0, -7*gitsense.DroughtBaselineWeeks)
+		recent := 0
+		for _, d := range dates {
+			if !d.Before(windowStart) {
+				recent++
+			}
+		}
+		if recent < gitsense.SilentMinCommits {
+			continue
+		}
+
+		var gaps []float64
+		for i := 1; i < len(dates); i++ {
+			gaps = append(gaps, dates[i].Sub(dates[i-1]).Hours()/24)
+		}
+		typicalGap, _ := medianMAD(gaps)
+
+		silentDays := now.Sub(last).Hours() / 24
+		if silentDays < gitsense.SilentMinDays || silentDays < gitsense.SilentGapFactor*math.Max(typicalGap, 1) {
+			continue
+		}
+
+		events = append(events, Event{
+			Kind:        KindContributorGone,
+			Subject:     author,
+			PeriodStart: truncateDay(last).Format(dayFormat),
+			PeriodEnd:   truncateDay(now).Format(dayFormat),
+			Observed:    round1(silentDays),
+			Expected:    round1(typicalGap),
+			Message:     fmt.Sprintf("%s has not committed for %.0f days (usually every %.1f days)", author, silentDays, typicalGap),
+		})
+	}
+	return events
+}
+
+func medianMAD(values []float64) (median, mad float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	median = percentile50(values)
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	return median, percentile50(deviations)
+}
+
+func percentile50(values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := len(sorted)
+	if n%2 == 1 {
+		return sorted[n/2]
+	}
+	return (sorted[n/2-1] + sorted[n/2]) / 2
+}
+
+func truncateDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+func weekStart(t time.Time) time.Time {
+	d := truncateDay(t)
+	return d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
+
+// ----------------------------
+// STORAGE
+// ----------------------------
+
+// DetectAndStore runs detection over a repo's stored commits, skipping authors
+// the detector excludes, and upserts the events. Re-running updates ongoing
+// events (e.g. a growing silence) instead of duplicating them. Every run
+// evaluates the whole history, so stored events it no longer finds (a
+// drought in a week that has since filled up, a silent contributor who came
+// back) are removed.
+func DetectAndStore(repo string, detector *bots.Detector) (int, error) {
+	rows, err := db.DB.Query(`
+		SELECT author, commit_date
+		FROM commits
+		WHERE repo_name = ?
+	`, repo)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load commits: %w", err)
+	}
+
+	var commits []Commit
+	for rows.Next() {
+		var author, commitDate string
+		if err := rows.Scan(&author, &commitDate); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan commit: %w", err)
+		}
+		if detector.Excludes(author) {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, commitDate)
+		if err != nil {
+			continue
+		}
+		commits = append(commits, Commit{Author: author, Date: t})
+	}
+	rows.Close()
+
+	events := Detect(commits, time.Now().UTC())
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	found := map[string]bool{}
+	for _, e := range events {
+		found[e.Kind+"\x00"+e.Subject+"\x00"+e.PeriodStart] = true
+		_, err := tx.Exec(`
+			INSERT INTO anomaly_events
+			(repo_name, kind, subject, period_start, period_end, observed, expected, message)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(repo_name, kind, subject, period_start)
+			DO UPDATE SET
+				period_end = excluded.period_end,
+				observed = excluded.observed,
+				expected = excluded.expected,
+				message = excluded.message
+		`, repo, e.Kind, e.Subject, e.PeriodStart, e.PeriodEnd, e.Observed, e.Expected, e.Message)
+		if err != nil {
+			return 0, fmt.Errorf("failed to save anomaly: %w", err)
+		}
+	}
+
+	stored, err := tx.Query(`
+		SELECT id, kind, subject, period_start
+		FROM anomaly_events
+		WHERE repo_name = ?
+	`, repo)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load anomalies: %w", err)
+	}
+	var resolved []int64
+	for stored.Next() {
+		var id int64
+		var kind, subject, periodStart string
+		if err := stored.Scan(&id, &kind, &subject, &periodStart); err != nil {
+			stored.Close()
+			return 0, fmt.Errorf("failed to scan anomaly: %w", err)
+		}
+		if !found[kind+"\x00"+subject+"\x00"+periodStart] {
+			resolved = append(resolved, id)
+		}
+	}
+	stored.Close()
+
+	for _, id := range resolved {
+		if _, err := tx.Exec(`DELETE FROM anomaly_events WHERE id = ?`, id); err != nil {
+			return 0, fmt.Errorf("failed to remove resolved anomaly: %w", err)
+		}
+	}
+	return len(events), tx.Commit()
+}
+
+// List returns stored events for a repo, newest first, optionally filtered by
+// kind. Only events whose period overlaps the range are returned.
+func List(repo, kind string, tr gitsense.TimeRange, limit int) ([]Event, error) {
+	from, to := "", tr.To.UTC().Format(dayFormat)
+	if tr.Bounded() {
+		from = tr.From.UTC().Format(dayFormat)
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT kind, subject, period_start, period_end, observed, expected, message, detected_at
+		FROM anomaly_events
+		WHERE repo_name = ? AND (? = '' OR kind = ?)
+		  AND period_end >= ? AND period_start <= ?
+		ORDER BY period_end DESC, period_start DESC
+		LIMIT ?
+	`, repo, kind, kind, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := []Event{}
+	for rows.Next() {
+		var e Event
+		if err := rows.Scan(&e.Kind, &e.Subject, &e.PeriodStart, &e.PeriodEnd, &e.Observed, &e.Expected, &e.Message, &e.DetectedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
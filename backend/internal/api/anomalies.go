@@ -0,0 +1,66 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gitsense"
+	"gitsense/internal/anomaly"
+	"gitsense/internal/bots"
+)
+
+// ----------------------------
+// ANOMALIES
+// Unusual activity periods detected during sync
+// ----------------------------
+func GetAnomalies(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	kind := r.URL.Query().Get("kind")
+	if kind != "" && kind != anomaly.KindSpike && kind != anomaly.KindDrought && kind != anomaly.KindContributorGone {
+		http.Error(w, "kind must be spike, drought or contributor_silent", 400)
+		return
+	}
+
+	limit, err := gitsense.ValidateIntParam(r, "limit", gitsense.DefaultAnomalyLimit, 1, gitsense.MaxAnomalyLimit)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	events, err := anomaly.List(repo, kind, tr, limit)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	// Silent-contributor events may predate the repo's bot settings
+	filtered := events[:0]
+	for _, e := range events {
+		if e.Kind == anomaly.KindContributorGone && detector.Excludes(e.Subject) {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+	events = filtered
+
+	tr.Echo(w)
+	json.NewEncoder(w).Encode(events)
+}
@@ -2,11 +2,20 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"sort"
 	"time"
 
+	"gitsense"
+	"gitsense/internal/activity"
+	"gitsense/internal/auth"
+	"gitsense/internal/bots"
 	"gitsense/internal/db"
 	"gitsense/internal/models"
+	"gitsense/internal/repos"
+	"gitsense/internal/scoring"
+	"gitsense/internal/settings"
 )
 
 func HealthHandler(w http.ResponseWriter, r *http.Request) {
@@ -14,60 +23,164 @@ func HealthHandler(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte("GitSense backend running 🚀"))
 }
 
+// ----------------------------
+// PROJECT SUMMARY
+// Activity split, score and score breakdown for one repo the authenticated
+// user has synced, as of the end of the requested range
+// ----------------------------
 func GetProjectSummary(w http.ResponseWriter, r *http.Request) {
-	rows, err := db.DB.Query(`SELECT last_modified FROM file_activity`)
+	gitsense.SetCORSHeaders(w)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	repo, err := gitsense.ValidateRepoParam(r)
 	if err != nil {
-		http.Error(w, "DB error", 500)
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	tr, err := summaryRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
 		return
 	}
-	defer rows.Close()
 
-	total, active, stable, inactive := 0, 0, 0, 0
+	_, userID, err := auth.Authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
 
-	for rows.Next() {
-		var lastModified string
-		rows.Scan(&lastModified)
+	tracked, err := repos.IsTracked(userID, repo)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+	if !tracked {
+		http.Error(w, "Repo has not been synced by this user", http.StatusForbidden)
+		return
+	}
 
-		t, err := time.Parse(time.RFC3339, lastModified)
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	// The model param previews another scoring model without saving it
+	var model scoring.Model
+	if name := r.URL.Query().Get("model"); name != "" {
+		model, err = scoring.Get(name)
 		if err != nil {
-			continue
+			http.Error(w, err.Error(), 400)
+			return
 		}
+	}
 
-		days := time.Since(t).Hours() / 24
-		total++
+	summary, err := activity.Summarize(repo, detector, &tr, model)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+	summary.Range = &tr
 
-		if days <= 7 {
-			active++
-		} else if days <= 30 {
-			stable++
-		} else {
-			inactive++
-		}
+	json.NewEncoder(w).Encode(summary)
+}
+
+// ----------------------------
+// PORTFOLIO SUMMARY
+// One summary per repo the authenticated user has synced, plus an aggregate
+// over all of their files
+// ----------------------------
+func GetPortfolioSummary(w http.ResponseWriter, r *http.Request) {
+	gitsense.SetCORSHeaders(w)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	tr, err := summaryRange(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	_, userID, err := auth.Authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
 	}
 
-	score := 0.0
-	if total > 0 {
-		rawScore := (float64(active) / float64(total)) * 100
-		score = float64(int(rawScore + 0.5)) // Round to nearest integer
+	repoNames, err := repos.TrackedRepos(userID)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	summaries := []models.ProjectSummary{}
+	aggregate := models.ProjectSummary{}
+	weightedScore := 0.0
+
+	for _, repo := range repoNames {
+		detector, err := bots.ForRequest(r, repo)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+
+		summary, err := activity.Summarize(repo, detector, &tr, nil)
+		if err != nil {
+			http.Error(w, "DB error", 500)
+			return
+		}
+		summaries = append(summaries, summary)
+
+		aggregate.TotalFiles += summary.TotalFiles
+		aggregate.ActiveFiles += summary.ActiveFiles
+		aggregate.StableFiles += summary.StableFiles
+		aggregate.InactiveFiles += summary.InactiveFiles
+		weightedScore += summary.ActivityScore * float64(summary.TotalFiles)
 	}
 
-	state := "STABLE"
-	if score > 50 {
-		state = "HIGH ACTIVITY"
-	} else if score > 25 {
-		state = "EVOLVING"
+	// Repos may use different scoring models, so the aggregate score is the
+	// file-weighted mean of their scores
+	if aggregate.TotalFiles > 0 {
+		aggregate.ActivityScore = gitsense.Round(weightedScore/float64(aggregate.TotalFiles), 0)
 	}
+	aggregate.ProjectState = scoring.State(aggregate.ActivityScore)
 
-	json.NewEncoder(w).Encode(models.ProjectSummary{
-		TotalFiles:    total,
-		ActiveFiles:   active,
-		StableFiles:   stable,
-		InactiveFiles: inactive,
-		ActivityScore: score,
-		ProjectState:  state,
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"repos":     summaries,
+		"aggregate": aggregate,
+		"range":     tr,
 	})
 }
 
+// summaryRange parses from/to, with as_of accepted as another name for to
+func summaryRange(r *http.Request) (gitsense.TimeRange, error) {
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		return tr, err
+	}
+
+	asOf, err := gitsense.ValidateAsOfParam(r)
+	if err != nil || asOf.IsZero() {
+		return tr, err
+	}
+	if r.URL.Query().Get("to") != "" {
+		return tr, fmt.Errorf("use either as_of or to, not both")
+	}
+	if tr.Bounded() && tr.From.After(asOf) {
+		return tr, fmt.Errorf("from must not be after as_of")
+	}
+	tr.To = asOf
+	return tr, nil
+}
+
 func GetRepoHistory(w http.ResponseWriter, r *http.Request) {
 	repo := r.URL.Query().Get("repo")
 
@@ -76,12 +189,20 @@ func GetRepoHistory(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	from, to := tr.Bounds()
 	rows, err := db.DB.Query(`
 		SELECT active_files, stable_files, inactive_files, activity_score, created_at
 		FROM repo_snapshots
 		WHERE repo_name = ?
+		  AND julianday(created_at) BETWEEN julianday(?) AND julianday(?)
 		ORDER BY created_at ASC
-	`, repo)
+	`, repo, from, to)
 
 	if err != nil {
 		http.Error(w, "DB error", 500)
@@ -107,6 +228,7 @@ func GetRepoHistory(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	tr.Echo(w)
 	json.NewEncoder(w).Encode(history)
 }
 
@@ -118,48 +240,94 @@ func GetFileActivity(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query(`
-		SELECT file_name, commit_count, last_modified
-		FROM file_activity
-		WHERE repo_name = ?
-		ORDER BY last_modified DESC
-	`, repo)
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
 
+	detector, err := bots.ForRequest(r, repo)
 	if err != nil {
-		http.Error(w, "DB error", 500)
+		http.Error(w, err.Error(), 400)
 		return
 	}
-	defer rows.Close()
 
-	var files []map[string]interface{}
+	thresholds, err := settings.GetThresholds(repo)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
 
-	for rows.Next() {
-		var fileName string
-		var commitCount int
-		var lastModified string
+	limit, err := gitsense.ValidateIntParam(r, "limit", gitsense.DefaultFileLimit, 1, gitsense.MaxFileLimit)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
 
-		rows.Scan(&fileName, &commitCount, &lastModified)
+	cursor, err := gitsense.ValidateCursorParam(r, 2)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
 
-		// Calculate file status based on last modified date
-		t, _ := time.Parse(time.RFC3339, lastModified)
-		days := time.Since(t).Hours() / 24
+	stats, err := activity.LoadFileStatsInRange(repo, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
 
-		status := "inactive"
-		if days <= 7 {
-			status = "active"
-		} else if days <= 30 {
-			status = "stable"
+	// Pages are keyed on (last_modified, file_name): most recently changed
+	// first, then by name
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].LastModified != stats[j].LastModified {
+			return stats[i].LastModified > stats[j].LastModified
 		}
+		return stats[i].Name < stats[j].Name
+	})
+
+	start := 0
+	if cursor != nil {
+		start = sort.Search(len(stats), func(i int) bool {
+			if stats[i].LastModified != cursor[0] {
+				return stats[i].LastModified < cursor[0]
+			}
+			return stats[i].Name > cursor[1]
+		})
+	}
+	end := start + limit
+	if end > len(stats) {
+		end = len(stats)
+	}
+
+	files := []map[string]interface{}{}
+
+	for _, f := range stats[start:end] {
+		// Calculate file status based on last modified date, as of the range end
+		t, _ := time.Parse(time.RFC3339, f.LastModified)
+		days := tr.To.Sub(t).Hours() / 24
+		status := thresholds.Classify(days)
 
 		files = append(files, map[string]interface{}{
-			"name":          fileName,
-			"commits":       commitCount,
-			"last_modified": lastModified,
+			"name":          f.Name,
+			"commits":       f.CommitCount,
+			"last_modified": f.LastModified,
 			"status":        status,
 		})
 	}
 
-	json.NewEncoder(w).Encode(files)
+	var nextCursor *string
+	if end < len(stats) {
+		last := stats[end-1]
+		next := gitsense.EncodeCursor(last.LastModified, last.Name)
+		nextCursor = &next
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"files":       files,
+		"next_cursor": nextCursor,
+		"total":       len(stats),
+		"range":       tr,
+	})
 }
 
 func DashboardHandler(w http.ResponseWriter, r *http.Request) {
@@ -177,13 +345,32 @@ func GetCommitsPerDay(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Days are bucketed in the requested zone (UTC by default)
+	loc, err := gitsense.ValidateTimezoneParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
 	rows, err := db.DB.Query(`
-		SELECT DATE(commit_date) as day, COUNT(*) as count
+		SELECT author, commit_date
 		FROM commits
 		WHERE repo_name = ?
-		GROUP BY DATE(commit_date)
-		ORDER BY day DESC
-		LIMIT 30
 	`, repo)
 
 	if err != nil {
@@ -192,20 +379,43 @@ func GetCommitsPerDay(w http.ResponseWriter, r *http.Request) {
 	}
 	defer rows.Close()
 
-	var data []map[string]interface{}
+	counts := map[string]int{}
 
 	for rows.Next() {
-		var day string
-		var count int
+		var author, commitDate string
 
-		rows.Scan(&day, &count)
+		rows.Scan(&author, &commitDate)
+		if detector.Excludes(author) {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, commitDate)
+		if err != nil || !tr.Contains(t) {
+			continue
+		}
+		counts[t.In(loc).Format("2006-01-02")]++
+	}
+
+	days := make([]string, 0, len(counts))
+	for day := range counts {
+		days = append(days, day)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(days)))
+	// Without an explicit start, only the 30 most recent active days are returned
+	if !tr.Bounded() && len(days) > 30 {
+		days = days[:30]
+	}
+
+	var data []map[string]interface{}
 
+	for _, day := range days {
 		data = append(data, map[string]interface{}{
 			"date":  day,
-			"count": count,
+			"count": counts[day],
 		})
 	}
 
+	tr.Echo(w)
 	json.NewEncoder(w).Encode(data)
 }
 
@@ -220,56 +430,83 @@ func GetFileBreakdown(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	rows, err := db.DB.Query(`
-		SELECT file_name, commit_count, last_modified
-		FROM file_activity
-		WHERE repo_name = ?
-		ORDER BY commit_count DESC
-	`, repo)
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
 
+	detector, err := bots.ForRequest(r, repo)
 	if err != nil {
-		http.Error(w, "DB error", 500)
+		http.Error(w, err.Error(), 400)
 		return
 	}
-	defer rows.Close()
 
 	type FileBreakdown struct {
 		Name         string `json:"name"`
 		CommitCount  int    `json:"commit_count"`
 		LastModified string `json:"last_modified"`
+		Churn        int    `json:"churn"`
 		Status       string `json:"status"`
 	}
 
+	thresholds, err := settings.GetThresholds(repo)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	stats, err := activity.LoadFileStatsInRange(repo, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	changes, err := loadFileChanges(repo, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	churn := map[string]int{}
+	for _, fc := range changes {
+		churn[fc.FileName] += fc.Additions + fc.Deletions
+	}
+
 	var mostModified []FileBreakdown
 	var inactive []FileBreakdown
 	var frequentlyUpdated []FileBreakdown
 	var allFiles []FileBreakdown
 
-	for rows.Next() {
-		var f FileBreakdown
-		rows.Scan(&f.Name, &f.CommitCount, &f.LastModified)
+	for _, stat := range stats {
+		f := FileBreakdown{
+			Name:         stat.Name,
+			CommitCount:  stat.CommitCount,
+			LastModified: stat.LastModified,
+			Churn:        churn[stat.Name],
+		}
 
-		// Calculate status based on last modified date
+		// Calculate status based on last modified date, as of the range end
 		t, _ := time.Parse(time.RFC3339, f.LastModified)
-		days := time.Since(t).Hours() / 24
-
-		if days <= 7 {
-			f.Status = "active"
-		} else if days <= 30 {
-			f.Status = "stable"
-		} else {
-			f.Status = "inactive"
-		}
+		days := tr.To.Sub(t).Hours() / 24
+		f.Status = thresholds.Classify(days)
 
 		allFiles = append(allFiles, f)
 	}
 
-	// Get top 10 most modified files
+	sort.SliceStable(allFiles, func(i, j int) bool {
+		if allFiles[i].Churn != allFiles[j].Churn {
+			return allFiles[i].Churn > allFiles[j].Churn
+		}
+		return allFiles[i].CommitCount > allFiles[j].CommitCount
+	})
+
+	// Get top 10 most modified files by lines changed, then by commit count
 	for i := 0; i < len(allFiles) && i < 10; i++ {
 		mostModified = append(mostModified, allFiles[i])
 	}
 
-	// Get inactive files (>30 days)
+	// Get inactive files (older than the repo's stable threshold)
 	for _, f := range allFiles {
 		if f.Status == "inactive" {
 			inactive = append(inactive, f)
@@ -287,6 +524,7 @@ func GetFileBreakdown(w http.ResponseWriter, r *http.Request) {
 		"most_modified":      mostModified,
 		"inactive":           inactive,
 		"frequently_updated": frequentlyUpdated,
+		"range":              tr,
 	}
 
 	json.NewEncoder(w).Encode(response)
@@ -303,13 +541,26 @@ func GetContributorDistribution(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	from, to := tr.Bounds()
 	rows, err := db.DB.Query(`
 		SELECT author, COUNT(*) as commit_count
 		FROM commits
-		WHERE repo_name = ?
+		WHERE repo_name = ? AND commit_date >= ? AND commit_date <= ?
 		GROUP BY author
 		ORDER BY commit_count DESC
-	`, repo)
+	`, repo, from, to)
 
 	if err != nil {
 		http.Error(w, "DB error", 500)
@@ -324,6 +575,9 @@ func GetContributorDistribution(w http.ResponseWriter, r *http.Request) {
 		var count int
 
 		rows.Scan(&author, &count)
+		if detector.Excludes(author) {
+			continue
+		}
 
 		contributors = append(contributors, map[string]interface{}{
 			"author":  author,
@@ -331,5 +585,6 @@ func GetContributorDistribution(w http.ResponseWriter, r *http.Request) {
 		})
 	}
 
+	tr.Echo(w)
 	json.NewEncoder(w).Encode(contributors)
 }
@@ -0,0 +1,370 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"gitsense"
+	"gitsense/internal/bots"
+	"gitsense/internal/db"
+	"gitsense/internal/ignore"
+)
+
+// fileChange is one file touched by one commit, with its line stats
+type fileChange struct {
+	SHA        string
+	Author     string
+	CommitDate string
+	FileName   string
+	Additions  int
+	Deletions  int
+}
+
+// loadFileChanges returns the per-commit file changes of a repo within the
+// range, skipping ignored paths and commits by authors the detector excludes
+func loadFileChanges(repo string, detector *bots.Detector, tr gitsense.TimeRange) ([]fileChange, error) {
+	matcher, err := ignore.ForRepo(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	from, to := tr.Bounds()
+	rows, err := db.DB.Query(`
+		SELECT cf.commit_sha, c.author, c.commit_date, cf.file_name, cf.additions, cf.deletions
+		FROM commit_files cf
+		JOIN commits c ON c.commit_sha = cf.commit_sha
+		WHERE cf.repo_name = ? AND c.commit_date >= ? AND c.commit_date <= ?
+		ORDER BY c.commit_date ASC
+	`, repo, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []fileChange
+	for rows.Next() {
+		var fc fileChange
+		if err := rows.Scan(&fc.SHA, &fc.Author, &fc.CommitDate, &fc.FileName, &fc.Additions, &fc.Deletions); err != nil {
+			return nil, err
+		}
+		if matcher.Ignored(fc.FileName) || detector.Excludes(fc.Author) {
+			continue
+		}
+		changes = append(changes, fc)
+	}
+	return changes, rows.Err()
+}
+
+type ChurnStats struct {
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+	Churn     int `json:"churn"`
+	Net       int `json:"net"`
+	Commits   int `json:"commits"`
+
+	shas map[string]bool
+}
+
+func (c *ChurnStats) add(fc fileChange) {
+	if c.shas == nil {
+		c.shas = map[string]bool{}
+	}
+	c.Additions += fc.Additions
+	c.Deletions += fc.Deletions
+	c.Churn = c.Additions + c.Deletions
+	c.Net = c.Additions - c.Deletions
+	if !c.shas[fc.SHA] {
+		c.shas[fc.SHA] = true
+		c.Commits++
+	}
+}
+
+// ----------------------------
+// CHURN PER DAY
+// Lines added/removed per day and cumulative net growth
+// ----------------------------
+func GetChurnPerDay(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	changes, err := loadFileChanges(repo, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	days := map[string]*ChurnStats{}
+	var order []string
+	for _, fc := range changes {
+		if len(fc.CommitDate) < 10 {
+			continue
+		}
+		day := fc.CommitDate[:10]
+		if days[day] == nil {
+			days[day] = &ChurnStats{}
+			order = append(order, day)
+		}
+		days[day].add(fc)
+	}
+	sort.Strings(order)
+
+	data := []map[string]interface{}{}
+	growth := 0
+	for _, day := range order {
+		s := days[day]
+		growth += s.Net
+		data = append(data, map[string]interface{}{
+			"date":       day,
+			"additions":  s.Additions,
+			"deletions":  s.Deletions,
+			"net":        s.Net,
+			"commits":    s.Commits,
+			"net_growth": growth,
+		})
+	}
+
+	tr.Echo(w)
+	json.NewEncoder(w).Encode(data)
+}
+
+// ----------------------------
+// CHURN PER FILE
+// ----------------------------
+func GetChurnByFile(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	limit, err := gitsense.ValidateIntParam(r, "limit", gitsense.DefaultChurnLimit, 1, gitsense.MaxChurnLimit)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	changes, err := loadFileChanges(repo, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	type FileChurn struct {
+		Name string `json:"name"`
+		ChurnStats
+	}
+
+	byFile := map[string]*FileChurn{}
+	for _, fc := range changes {
+		if byFile[fc.FileName] == nil {
+			byFile[fc.FileName] = &FileChurn{Name: fc.FileName}
+		}
+		byFile[fc.FileName].add(fc)
+	}
+
+	files := []FileChurn{}
+	for _, f := range byFile {
+		files = append(files, *f)
+	}
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Churn != files[j].Churn {
+			return files[i].Churn > files[j].Churn
+		}
+		return files[i].Name < files[j].Name
+	})
+	if len(files) > limit {
+		files = files[:limit]
+	}
+
+	tr.Echo(w)
+	json.NewEncoder(w).Encode(files)
+}
+
+// ----------------------------
+// CHURN PER AUTHOR
+// ----------------------------
+func GetChurnByAuthor(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	changes, err := loadFileChanges(repo, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	type AuthorChurn struct {
+		Author string `json:"author"`
+		ChurnStats
+	}
+
+	byAuthor := map[string]*AuthorChurn{}
+	for _, fc := range changes {
+		if byAuthor[fc.Author] == nil {
+			byAuthor[fc.Author] = &AuthorChurn{Author: fc.Author}
+		}
+		byAuthor[fc.Author].add(fc)
+	}
+
+	authors := []AuthorChurn{}
+	for _, a := range byAuthor {
+		authors = append(authors, *a)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Churn != authors[j].Churn {
+			return authors[i].Churn > authors[j].Churn
+		}
+		return authors[i].Author < authors[j].Author
+	})
+
+	tr.Echo(w)
+	json.NewEncoder(w).Encode(authors)
+}
+
+// ----------------------------
+// CHURN VS COMMIT COUNT
+// Ranks files by both measures so heavily touched but small-change files
+// stand out from files with few but large rewrites
+// ----------------------------
+func GetChurnComparison(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	limit, err := gitsense.ValidateIntParam(r, "limit", gitsense.DefaultChurnLimit, 1, gitsense.MaxChurnLimit)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	changes, err := loadFileChanges(repo, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	type FileComparison struct {
+		Name           string  `json:"name"`
+		Commits        int     `json:"commits"`
+		Churn          int     `json:"churn"`
+		ChurnPerCommit float64 `json:"churn_per_commit"`
+		CommitRank     int     `json:"commit_rank"`
+		ChurnRank      int     `json:"churn_rank"`
+	}
+
+	byFile := map[string]*ChurnStats{}
+	for _, fc := range changes {
+		if byFile[fc.FileName] == nil {
+			byFile[fc.FileName] = &ChurnStats{}
+		}
+		byFile[fc.FileName].add(fc)
+	}
+
+	files := []*FileComparison{}
+	for name, s := range byFile {
+		f := &FileComparison{Name: name, Commits: s.Commits, Churn: s.Churn}
+		if s.Commits > 0 {
+			f.ChurnPerCommit = gitsense.Round(float64(s.Churn)/float64(s.Commits), 1)
+		}
+		files = append(files, f)
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Commits != files[j].Commits {
+			return files[i].Commits > files[j].Commits
+		}
+		return files[i].Name < files[j].Name
+	})
+	for i, f := range files {
+		f.CommitRank = i + 1
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if files[i].Churn != files[j].Churn {
+			return files[i].Churn > files[j].Churn
+		}
+		return files[i].Name < files[j].Name
+	})
+	for i, f := range files {
+		f.ChurnRank = i + 1
+	}
+
+	if len(files) > limit {
+		files = files[:limit]
+	}
+
+	totalChurn := 0
+	shas := map[string]bool{}
+	for _, fc := range changes {
+		totalChurn += fc.Additions + fc.Deletions
+		shas[fc.SHA] = true
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"total_commits": len(shas),
+		"total_churn":   totalChurn,
+		"files":         files,
+		"range":         tr,
+	})
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"gitsense"
+	"gitsense/internal/bots"
+	"gitsense/internal/db"
+)
+
+// ----------------------------
+// CONTRIBUTOR COHORTS
+// Per month: first-time, returning and lapsed contributors (active the
+// previous month but not this one), plus retention curves per cohort
+// (the month of a contributor's first commit)
+// ----------------------------
+func GetContributorCohorts(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT author, commit_date
+		FROM commits
+		WHERE repo_name = ?
+	`, repo)
+
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+	defer rows.Close()
+
+	activeMonths := map[string]map[string]bool{} // month -> set of authors
+	firstMonth := map[string]string{}            // author -> cohort month
+
+	for rows.Next() {
+		var author, commitDate string
+		rows.Scan(&author, &commitDate)
+
+		if detector.Excludes(author) {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, commitDate)
+		if err != nil {
+			continue
+		}
+		month := t.UTC().Format("2006-01")
+
+		// Cohorts are set by a contributor's first commit ever, even when
+		// it falls before the requested range
+		if first, ok := firstMonth[author]; !ok || month < first {
+			firstMonth[author] = month
+		}
+		if !tr.Contains(t) {
+			continue
+		}
+
+		if activeMonths[month] == nil {
+			activeMonths[month] = map[string]bool{}
+		}
+		activeMonths[month][author] = true
+	}
+
+	if len(activeMonths) == 0 {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"months":  []interface{}{},
+			"cohorts": []interface{}{},
+			"range":   tr,
+		})
+		return
+	}
+
+	// Every calendar month from the first commit to the last, gaps included
+	var observed []string
+	for m := range activeMonths {
+		observed = append(observed, m)
+	}
+	sort.Strings(observed)
+	months := monthRange(observed[0], observed[len(observed)-1])
+
+	type MonthSummary struct {
+		Month         string   `json:"month"`
+		Active        int      `json:"active"`
+		New           int      `json:"new"`
+		Returning     int      `json:"returning"`
+		WentInactive  int      `json:"went_inactive"`
+		NewNames      []string `json:"new_contributors"`
+		InactiveNames []string `json:"inactive_contributors"`
+	}
+
+	summaries := []MonthSummary{}
+	for i, month := range months {
+		s := MonthSummary{Month: month, NewNames: []string{}, InactiveNames: []string{}}
+		for author := range activeMonths[month] {
+			s.Active++
+			if firstMonth[author] == month {
+				s.New++
+				s.NewNames = append(s.NewNames, author)
+			} else {
+				s.Returning++
+			}
+		}
+		if i > 0 {
+			for author := range activeMonths[months[i-1]] {
+				if !activeMonths[month][author] {
+					s.WentInactive++
+					s.InactiveNames = append(s.InactiveNames, author)
+				}
+			}
+		}
+		sort.Strings(s.NewNames)
+		sort.Strings(s.InactiveNames)
+		summaries = append(summaries, s)
+	}
+
+	type Cohort struct {
+		Cohort    string    `json:"cohort"`
+		Size      int       `json:"size"`
+		Retention []float64 `json:"retention"`
+	}
+
+	cohorts := []Cohort{}
+	for i, month := range months {
+		var members []string
+		for author, first := range firstMonth {
+			if first == month {
+				members = append(members, author)
+			}
+		}
+		if len(members) == 0 {
+			continue
+		}
+
+		c := Cohort{Cohort: month, Size: len(members)}
+		for _, later := range months[i:] {
+			retained := 0
+			for _, author := range members {
+				if activeMonths[later][author] {
+					retained++
+				}
+			}
+			c.Retention = append(c.Retention, gitsense.Round(float64(retained)/float64(len(members))*100, 1))
+		}
+		cohorts = append(cohorts, c)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"months":  summaries,
+		"cohorts": cohorts,
+		"range":   tr,
+	})
+}
+
+// monthRange lists every YYYY-MM month from first to last inclusive
+func monthRange(first, last string) []string {
+	start, err1 := time.Parse("2006-01", first)
+	end, err2 := time.Parse("2006-01", last)
+	if err1 != nil || err2 != nil {
+		return nil
+	}
+
+	var months []string
+	for m := start; !m.After(end); m = m.AddDate(0, 1, 0) {
+		months = append(months, m.Format("2006-01"))
+	}
+	return months
+}
@@ -0,0 +1,227 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"gitsense"
+	"gitsense/internal/bots"
+	"gitsense/internal/commitkind"
+	"gitsense/internal/db"
+)
+
+// TypeMix counts commits per conventional commit type
+type TypeMix struct {
+	Total    int                `json:"total"`
+	Breaking int                `json:"breaking"`
+	Types    map[string]int     `json:"types"`
+	Shares   map[string]float64 `json:"shares"`
+}
+
+func newTypeMix() *TypeMix {
+	return &TypeMix{Types: map[string]int{}, Shares: map[string]float64{}}
+}
+
+func (m *TypeMix) add(c commitkind.Classification) {
+	m.Total++
+	m.Types[c.Type]++
+	if c.Breaking {
+		m.Breaking++
+	}
+}
+
+// finalize fills in the percentage share of each type
+func (m *TypeMix) finalize() {
+	for kind, n := range m.Types {
+		m.Shares[kind] = gitsense.Round(float64(n)/float64(m.Total)*100, 1)
+	}
+}
+
+type classifiedCommit struct {
+	Author string
+	Date   time.Time
+	commitkind.Classification
+}
+
+func loadClassifiedCommits(repo string, detector *bots.Detector, tr gitsense.TimeRange) ([]classifiedCommit, error) {
+	from, to := tr.Bounds()
+	rows, err := db.DB.Query(`
+		SELECT author, message, commit_date
+		FROM commits
+		WHERE repo_name = ? AND commit_date >= ? AND commit_date <= ?
+		ORDER BY commit_date ASC
+	`, repo, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var commits []classifiedCommit
+	for rows.Next() {
+		var author, message, commitDate string
+		if err := rows.Scan(&author, &message, &commitDate); err != nil {
+			return nil, err
+		}
+		if detector.Excludes(author) {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, commitDate)
+		if err != nil {
+			continue
+		}
+		commits = append(commits, classifiedCommit{
+			Author:         author,
+			Date:           t,
+			Classification: commitkind.Classify(message),
+		})
+	}
+	return commits, rows.Err()
+}
+
+// ----------------------------
+// COMMIT TYPE BREAKDOWN
+// Conventional commit type mix overall and per week or month
+// ----------------------------
+func GetCommitTypes(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	interval := r.URL.Query().Get("interval")
+	if interval == "" {
+		interval = "week"
+	}
+	if interval != "week" && interval != "month" {
+		http.Error(w, "interval must be week or month", 400)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	commits, err := loadClassifiedCommits(repo, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	overall := newTypeMix()
+	conventional := 0
+	periods := map[string]*TypeMix{}
+	var order []string
+
+	for _, c := range commits {
+		overall.add(c.Classification)
+		if c.Conventional {
+			conventional++
+		}
+
+		period := periodStart(c.Date, interval)
+		if periods[period] == nil {
+			periods[period] = newTypeMix()
+			order = append(order, period)
+		}
+		periods[period].add(c.Classification)
+	}
+	overall.finalize()
+	sort.Strings(order)
+
+	timeline := []map[string]interface{}{}
+	for _, p := range order {
+		mix := periods[p]
+		mix.finalize()
+		timeline = append(timeline, map[string]interface{}{
+			"period":   p,
+			"total":    mix.Total,
+			"breaking": mix.Breaking,
+			"types":    mix.Types,
+			"shares":   mix.Shares,
+		})
+	}
+
+	conventionalShare := 0.0
+	if overall.Total > 0 {
+		conventionalShare = gitsense.Round(float64(conventional)/float64(overall.Total)*100, 1)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"interval":           interval,
+		"types":              commitkind.Types,
+		"overall":            overall,
+		"conventional_share": conventionalShare,
+		"timeline":           timeline,
+		"range":              tr,
+	})
+}
+
+// ----------------------------
+// COMMIT TYPES PER CONTRIBUTOR
+// ----------------------------
+func GetCommitTypesByContributor(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	commits, err := loadClassifiedCommits(repo, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	byAuthor := map[string]*TypeMix{}
+	for _, c := range commits {
+		if byAuthor[c.Author] == nil {
+			byAuthor[c.Author] = newTypeMix()
+		}
+		byAuthor[c.Author].add(c.Classification)
+	}
+
+	type ContributorTypes struct {
+		Author string `json:"author"`
+		*TypeMix
+	}
+
+	contributors := []ContributorTypes{}
+	for author, mix := range byAuthor {
+		mix.finalize()
+		contributors = append(contributors, ContributorTypes{Author: author, TypeMix: mix})
+	}
+	sort.Slice(contributors, func(i, j int) bool {
+		if contributors[i].Total != contributors[j].Total {
+			return contributors[i].Total > contributors[j].Total
+		}
+		return contributors[i].Author < contributors[j].Author
+	})
+
+	tr.Echo(w)
+	json.NewEncoder(w).Encode(contributors)
+}
@@ -0,0 +1,222 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"gitsense"
+	"gitsense/internal/activity"
+	"gitsense/internal/bots"
+	"gitsense/internal/db"
+	"gitsense/internal/models"
+)
+
+// RepoComparison is one repo's series and totals over the comparison window.
+// Series are aligned with the response's dates.
+type RepoComparison struct {
+	Repo          string                `json:"repo"`
+	ActivityScore []*float64            `json:"activity_score"`
+	CommitsPerDay []int                 `json:"commits_per_day"`
+	Commits       int                   `json:"commits"`
+	Contributors  int                   `json:"contributors"`
+	Churn         ChurnStats            `json:"churn"`
+	FileStates    models.ProjectSummary `json:"file_states"`
+	Normalized    NormalizedComparison  `json:"normalized"`
+}
+
+// NormalizedComparison scales a repo's totals by its size so repos of
+// different sizes can be compared directly
+type NormalizedComparison struct {
+	CommitsPerFile        float64 `json:"commits_per_file"`
+	ChurnPerFile          float64 `json:"churn_per_file"`
+	CommitsPerContributor float64 `json:"commits_per_contributor"`
+	ActiveShare           float64 `json:"active_share"`
+	StableShare           float64 `json:"stable_share"`
+	InactiveShare         float64 `json:"inactive_share"`
+}
+
+// ----------------------------
+// REPO COMPARISON
+// Score history, commits per day, contributors, churn and file states of
+// several repos over the same window (repeatable repo param)
+// ----------------------------
+func GetRepoComparison(w http.ResponseWriter, r *http.Request) {
+	var repoNames []string
+	seen := map[string]bool{}
+	for _, repo := range r.URL.Query()["repo"] {
+		if repo != "" && !seen[repo] {
+			seen[repo] = true
+			repoNames = append(repoNames, repo)
+		}
+	}
+	if len(repoNames) < 2 || len(repoNames) > gitsense.MaxCompareRepos {
+		http.Error(w, fmt.Sprintf("between 2 and %d distinct repo parameters required", gitsense.MaxCompareRepos), 400)
+		return
+	}
+
+	days, err := gitsense.ValidateIntParam(r, "days", gitsense.DefaultCompareDays, 1, gitsense.MaxThresholdDays)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	// from/to take precedence over days
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	end := tr.To.Truncate(24 * time.Hour)
+	start := end.AddDate(0, 0, -(days - 1))
+	if tr.Bounded() {
+		start = tr.From.Truncate(24 * time.Hour)
+		if span := int(end.Sub(start).Hours()/24) + 1; span > gitsense.MaxThresholdDays {
+			http.Error(w, fmt.Sprintf("range must not span more than %d days", gitsense.MaxThresholdDays), 400)
+			return
+		}
+	}
+	tr.From = start
+
+	dates := []string{}
+	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
+		dates = append(dates, d.Format("2006-01-02"))
+	}
+
+	comparisons := []RepoComparison{}
+	for _, repo := range repoNames {
+		detector, err := bots.ForRequest(r, repo)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+
+		c, err := compareRepo(repo, detector, tr, dates)
+		if err != nil {
+			http.Error(w, "DB error", 500)
+			return
+		}
+		comparisons = append(comparisons, c)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"from":  dates[0],
+		"to":    dates[len(dates)-1],
+		"dates": dates,
+		"repos": comparisons,
+		"range": tr,
+	})
+}
+
+// compareRepo builds one repo's comparison over the given consecutive days,
+// which span tr
+func compareRepo(repo string, detector *bots.Detector, tr gitsense.TimeRange, dates []string) (RepoComparison, error) {
+	c := RepoComparison{
+		Repo:          repo,
+		ActivityScore: make([]*float64, len(dates)),
+		CommitsPerDay: make([]int, len(dates)),
+	}
+	index := map[string]int{}
+	for i, d := range dates {
+		index[d] = i
+	}
+	from, to := dates[0], dates[len(dates)-1]
+
+	// Score history: last snapshot per day, carried forward over gaps,
+	// including the latest snapshot before the window
+	rows, err := db.DB.Query(`
+		SELECT DATE(created_at), activity_score
+		FROM repo_snapshots
+		WHERE repo_name = ? AND DATE(created_at) <= ?
+		ORDER BY created_at ASC
+	`, repo, to)
+	if err != nil {
+		return c, err
+	}
+	byDay := map[string]float64{}
+	var carried *float64
+	for rows.Next() {
+		var day string
+		var score float64
+		if err := rows.Scan(&day, &score); err != nil {
+			rows.Close()
+			return c, err
+		}
+		if day < from {
+			s := score
+			carried = &s
+			continue
+		}
+		byDay[day] = score
+	}
+	rows.Close()
+
+	for i, d := range dates {
+		if score, ok := byDay[d]; ok {
+			s := score
+			carried = &s
+		}
+		c.ActivityScore[i] = carried
+	}
+
+	// Commits and contributors
+	rows, err = db.DB.Query(`
+		SELECT author, commit_date FROM commits WHERE repo_name = ?
+	`, repo)
+	if err != nil {
+		return c, err
+	}
+	authors := map[string]bool{}
+	for rows.Next() {
+		var author, commitDate string
+		if err := rows.Scan(&author, &commitDate); err != nil {
+			rows.Close()
+			return c, err
+		}
+		if detector.Excludes(author) {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, commitDate)
+		if err != nil {
+			continue
+		}
+		i, ok := index[t.UTC().Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		c.CommitsPerDay[i]++
+		c.Commits++
+		authors[author] = true
+	}
+	rows.Close()
+	c.Contributors = len(authors)
+
+	// Churn
+	changes, err := loadFileChanges(repo, detector, tr)
+	if err != nil {
+		return c, err
+	}
+	for _, fc := range changes {
+		c.Churn.add(fc)
+	}
+
+	// File state distribution at the end of the window
+	c.FileStates, err = activity.Summarize(repo, detector, &gitsense.TimeRange{To: tr.To}, nil)
+	if err != nil {
+		return c, err
+	}
+	c.FileStates.Repo = ""
+
+	if total := c.FileStates.TotalFiles; total > 0 {
+		c.Normalized.CommitsPerFile = gitsense.Round(float64(c.Commits)/float64(total), 3)
+		c.Normalized.ChurnPerFile = gitsense.Round(float64(c.Churn.Churn)/float64(total), 1)
+		c.Normalized.ActiveShare = gitsense.Round(float64(c.FileStates.ActiveFiles)/float64(total)*100, 1)
+		c.Normalized.StableShare = gitsense.Round(float64(c.FileStates.StableFiles)/float64(total)*100, 1)
+		c.Normalized.InactiveShare = gitsense.Round(float64(c.FileStates.InactiveFiles)/float64(total)*100, 1)
+	}
+	if c.Contributors > 0 {
+		c.Normalized.CommitsPerContributor = gitsense.Round(float64(c.Commits)/float64(c.Contributors), 1)
+	}
+	return c, nil
+}
@@ -0,0 +1,278 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"gitsense"
+	"gitsense/internal/auth"
+	"gitsense/internal/commitkind"
+	"gitsense/internal/db"
+	"gitsense/internal/ignore"
+	"gitsense/internal/repos"
+)
+
+type TouchCount struct {
+	Path    string `json:"path"`
+	Commits int    `json:"commits"`
+}
+
+// ----------------------------
+// CONTRIBUTOR PROFILE
+// Activity timeline for one author, in a single repo (repo param) or across
+// every repo the authenticated user has synced (no repo param)
+// ----------------------------
+func GetContributorProfile(w http.ResponseWriter, r *http.Request) {
+	gitsense.SetCORSHeaders(w)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	author := r.URL.Query().Get("author")
+	if author == "" {
+		http.Error(w, "Author required", 400)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	repoNames := []string{}
+	if repo := r.URL.Query().Get("repo"); repo != "" {
+		repoNames = append(repoNames, repo)
+	} else {
+		_, userID, err := auth.Authenticate(r)
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		repoNames, err = repos.TrackedRepos(userID)
+		if err != nil {
+			http.Error(w, "DB error", 500)
+			return
+		}
+	}
+
+	profile, err := buildContributorProfile(author, repoNames, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+	if profile == nil {
+		http.Error(w, "No commits found for author", 404)
+		return
+	}
+	profile["range"] = tr
+
+	json.NewEncoder(w).Encode(profile)
+}
+
+func buildContributorProfile(author string, repoNames []string, tr gitsense.TimeRange) (map[string]interface{}, error) {
+	if len(repoNames) == 0 {
+		return nil, nil
+	}
+
+	placeholders, args := repoInClause(author, repoNames)
+	from, to := tr.Bounds()
+	args = append(args, from, to)
+
+	rows, err := db.DB.Query(`
+		SELECT repo_name, commit_sha, message, commit_date
+		FROM commits
+		WHERE author = ? AND repo_name IN (`+placeholders+`)
+		  AND commit_date >= ? AND commit_date <= ?
+		ORDER BY commit_date ASC
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	var dates []time.Time
+	days := map[string]bool{}
+	weeks := map[string]int{}
+	perRepo := map[string]int{}
+	types := newTypeMix()
+
+	for rows.Next() {
+		var repo, sha, message, commitDate string
+		if err := rows.Scan(&repo, &sha, &message, &commitDate); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		t, err := time.Parse(time.RFC3339, commitDate)
+		if err != nil {
+			continue
+		}
+
+		dates = append(dates, t)
+		days[t.UTC().Format("2006-01-02")] = true
+		weeks[periodStart(t, "week")]++
+		perRepo[repo]++
+		types.add(commitkind.Classify(message))
+	}
+	rows.Close()
+
+	if len(dates) == 0 {
+		return nil, nil
+	}
+	types.finalize()
+
+	files, directories, err := contributorTouches(author, repoNames, tr)
+	if err != nil {
+		return nil, err
+	}
+
+	// Weekly series including the zero weeks between first and last commit
+	var perWeek []map[string]interface{}
+	for wk := weekStartOf(dates[0]); !wk.After(dates[len(dates)-1]); wk = wk.AddDate(0, 0, 7) {
+		key := wk.Format("2006-01-02")
+		perWeek = append(perWeek, map[string]interface{}{
+			"week":    key,
+			"commits": weeks[key],
+		})
+	}
+
+	repoCounts := []map[string]interface{}{}
+	for _, name := range repoNames {
+		if perRepo[name] > 0 {
+			repoCounts = append(repoCounts, map[string]interface{}{
+				"repo":    name,
+				"commits": perRepo[name],
+			})
+		}
+	}
+
+	return map[string]interface{}{
+		"author":           author,
+		"repos":            repoCounts,
+		"total_commits":    len(dates),
+		"first_commit":     dates[0].UTC().Format(time.RFC3339),
+		"last_commit":      dates[len(dates)-1].UTC().Format(time.RFC3339),
+		"active_days":      len(days),
+		"longest_streak":   longestStreak(days),
+		"commits_per_week": perWeek,
+		"top_files":        files,
+		"top_directories":  directories,
+		"commit_types":     types,
+	}, nil
+}
+
+// contributorTouches counts the distinct commits in which the author touched
+// each file and each top-level directory within the range, skipping ignored paths
+func contributorTouches(author string, repoNames []string, tr gitsense.TimeRange) ([]TouchCount, []TouchCount, error) {
+	placeholders, args := repoInClause(author, repoNames)
+	from, to := tr.Bounds()
+	args = append(args, from, to)
+
+	rows, err := db.DB.Query(`
+		SELECT cf.repo_name, cf.file_name, cf.commit_sha
+		FROM commit_files cf
+		JOIN commits c ON c.commit_sha = cf.commit_sha
+		WHERE c.author = ? AND cf.repo_name IN (`+placeholders+`)
+		  AND c.commit_date >= ? AND c.commit_date <= ?
+	`, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	multiRepo := len(repoNames) > 1
+	matchers := ignore.MatcherCache{}
+	fileCommits := map[string]int{}
+	dirCommits := map[string]map[string]bool{}
+
+	for rows.Next() {
+		var repo, fileName, sha string
+		if err := rows.Scan(&repo, &fileName, &sha); err != nil {
+			return nil, nil, err
+		}
+		if matchers.Get(repo).Ignored(fileName) {
+			continue
+		}
+
+		path, dir := fileName, directoryOf(fileName, 1)
+		if multiRepo {
+			path = repo + ":" + fileName
+			dir = repo + ":" + dir
+		}
+
+		fileCommits[path]++
+		if dirCommits[dir] == nil {
+			dirCommits[dir] = map[string]bool{}
+		}
+		dirCommits[dir][sha] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	dirCounts := map[string]int{}
+	for dir, shas := range dirCommits {
+		dirCounts[dir] = len(shas)
+	}
+	return topTouches(fileCommits), topTouches(dirCounts), nil
+}
+
+// repoInClause returns "?,?,..." placeholders for the repo names and the
+// query args, prefixed with any leading args
+func repoInClause(leading interface{}, repoNames []string) (string, []interface{}) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(repoNames)), ",")
+	args := []interface{}{leading}
+	for _, name := range repoNames {
+		args = append(args, name)
+	}
+	return placeholders, args
+}
+
+func topTouches(counts map[string]int) []TouchCount {
+	touches := []TouchCount{}
+	for path, n := range counts {
+		touches = append(touches, TouchCount{Path: path, Commits: n})
+	}
+	sort.Slice(touches, func(i, j int) bool {
+		if touches[i].Commits != touches[j].Commits {
+			return touches[i].Commits > touches[j].Commits
+		}
+		return touches[i].Path < touches[j].Path
+	})
+	if len(touches) > gitsense.ProfileTopPaths {
+		touches = touches[:gitsense.ProfileTopPaths]
+	}
+	return touches
+}
+
+// longestStreak returns the longest run of consecutive days in the set
+func longestStreak(days map[string]bool) int {
+	longest := 0
+	for day := range days {
+		t, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			continue
+		}
+		// Only start counting at the first day of a run
+		if days[t.AddDate(0, 0, -1).Format("2006-01-02")] {
+			continue
+		}
+		run := 1
+		for days[t.AddDate(0, 0, run).Format("2006-01-02")] {
+			run++
+		}
+		if run > longest {
+			longest = run
+		}
+	}
+	return longest
+}
+
+func weekStartOf(t time.Time) time.Time {
+	start, _ := time.Parse("2006-01-02", periodStart(t, "week"))
+	return start
+}
@@ -0,0 +1,308 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"gitsense"
+	"gitsense/internal/bots"
+)
+
+type CoupledPair struct {
+	FileA          string  `json:"file_a"`
+	FileB          string  `json:"file_b"`
+	Support        int     `json:"support"`
+	CommitsA       int     `json:"commits_a"`
+	CommitsB       int     `json:"commits_b"`
+	ConfidenceAToB float64 `json:"confidence_a_to_b"`
+	ConfidenceBToA float64 `json:"confidence_b_to_a"`
+	CrossDirectory bool    `json:"cross_directory"`
+}
+
+type couplingParams struct {
+	days           int // 0 when from replaces the days window
+	minSupport     int
+	minConfidence  float64
+	crossDirectory bool
+	limit          int
+	tr             gitsense.TimeRange
+}
+
+func parseCouplingParams(r *http.Request) (couplingParams, error) {
+	p := couplingParams{}
+	var err error
+
+	p.days, err = gitsense.ValidateIntParam(r, "days", gitsense.DefaultCouplingDays, 1, gitsense.MaxThresholdDays)
+	if err != nil {
+		return p, err
+	}
+
+	p.minSupport, err = gitsense.ValidateIntParam(r, "min_support", gitsense.DefaultCouplingMinSupport, 1, gitsense.MaxCommitLimit)
+	if err != nil {
+		return p, err
+	}
+
+	p.limit, err = gitsense.ValidateIntParam(r, "limit", gitsense.DefaultChurnLimit, 1, gitsense.MaxChurnLimit)
+	if err != nil {
+		return p, err
+	}
+
+	p.minConfidence = gitsense.DefaultCouplingMinConfidence
+	if v := r.URL.Query().Get("min_confidence"); v != "" {
+		p.minConfidence, err = strconv.ParseFloat(v, 64)
+		if err != nil || p.minConfidence < 0 || p.minConfidence > 1 {
+			return p, fmt.Errorf("min_confidence must be a number between 0 and 1")
+		}
+	}
+
+	p.crossDirectory = r.URL.Query().Get("cross_directory") == "true"
+
+	// from/to take precedence over days
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		return p, err
+	}
+	p.tr = tr.WithDefaultDays(p.days)
+	if tr.Bounded() {
+		p.days = 0
+	}
+	return p, nil
+}
+
+// computeCoupling counts how often pairs of files change in the same commit.
+// Commits touching more than MaxCouplingCommitFiles files (mass renames,
+// reformatting) are skipped since they couple everything with everything.
+func computeCoupling(repo string, p couplingParams, detector *bots.Detector) ([]CoupledPair, error) {
+	changes, err := loadFileChanges(repo, detector, p.tr)
+	if err != nil {
+		return nil, err
+	}
+
+	filesByCommit := map[string][]string{}
+	for _, fc := range changes {
+		filesByCommit[fc.SHA] = append(filesByCommit[fc.SHA], fc.FileName)
+	}
+
+	commitsPerFile := map[string]int{}
+	pairCounts := map[[2]string]int{}
+	for _, files := range filesByCommit {
+		// Skipped mass commits don't count towards confidence either;
+		// single-file commits do, as changes without a partner
+		if len(files) > gitsense.MaxCouplingCommitFiles {
+			continue
+		}
+		for _, f := range files {
+			commitsPerFile[f]++
+		}
+		if len(files) < 2 {
+			continue
+		}
+		sort.Strings(files)
+		for i := 0; i < len(files); i++ {
+			for j := i + 1; j < len(files); j++ {
+				pairCounts[[2]string{files[i], files[j]}]++
+			}
+		}
+	}
+
+	pairs := []CoupledPair{}
+	for key, support := range pairCounts {
+		if support < p.minSupport {
+			continue
+		}
+
+		pair := CoupledPair{
+			FileA:          key[0],
+			FileB:          key[1],
+			Support:        support,
+			CommitsA:       commitsPerFile[key[0]],
+			CommitsB:       commitsPerFile[key[1]],
+			CrossDirectory: directoryOf(key[0], gitsense.MaxTreeDepth) != directoryOf(key[1], gitsense.MaxTreeDepth),
+		}
+		pair.ConfidenceAToB = gitsense.Round(float64(support)/float64(pair.CommitsA), 2)
+		pair.ConfidenceBToA = gitsense.Round(float64(support)/float64(pair.CommitsB), 2)
+
+		if pair.ConfidenceAToB < p.minConfidence && pair.ConfidenceBToA < p.minConfidence {
+			continue
+		}
+		if p.crossDirectory && !pair.CrossDirectory {
+			continue
+		}
+		pairs = append(pairs, pair)
+	}
+
+	sort.Slice(pairs, func(i, j int) bool {
+		if pairs[i].Support != pairs[j].Support {
+			return pairs[i].Support > pairs[j].Support
+		}
+		if pairs[i].FileA != pairs[j].FileA {
+			return pairs[i].FileA < pairs[j].FileA
+		}
+		return pairs[i].FileB < pairs[j].FileB
+	})
+	if len(pairs) > p.limit {
+		pairs = pairs[:p.limit]
+	}
+	return pairs, nil
+}
+
+// ----------------------------
+// CHANGE COUPLING
+// File pairs that are frequently modified in the same commit
+// ----------------------------
+func GetChangeCoupling(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	params, err := parseCouplingParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	pairs, err := computeCoupling(repo, params, detector)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	response := map[string]interface{}{
+		"min_support":    params.minSupport,
+		"min_confidence": params.minConfidence,
+		"pairs":          pairs,
+		"range":          params.tr,
+	}
+	if params.days > 0 {
+		response["days"] = params.days
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// ----------------------------
+// CHANGE COUPLING GRAPH
+// Same analysis exported as DOT (default) or GraphML
+// ----------------------------
+func GetChangeCouplingGraph(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "dot"
+	}
+	if format != "dot" && format != "graphml" {
+		http.Error(w, "format must be dot or graphml", 400)
+		return
+	}
+
+	params, err := parseCouplingParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	pairs, err := computeCoupling(repo, params, detector)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	params.tr.Echo(w)
+	if format == "graphml" {
+		w.Header().Set("Content-Type", "application/graphml+xml")
+		w.Write(couplingGraphML(pairs))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/vnd.graphviz")
+	w.Write(couplingDOT(pairs))
+}
+
+func couplingDOT(pairs []CoupledPair) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("graph coupling {\n")
+	for _, p := range pairs {
+		fmt.Fprintf(&buf, "  %s -- %s [weight=%d, label=\"%d\"];\n",
+			dotID(p.FileA), dotID(p.FileB), p.Support, p.Support)
+	}
+	buf.WriteString("}\n")
+	return buf.Bytes()
+}
+
+// dotID quotes a file path as a DOT identifier
+func dotID(s string) string {
+	var buf bytes.Buffer
+	buf.WriteByte('"')
+	for _, c := range s {
+		if c == '"' || c == '\\' {
+			buf.WriteByte('\\')
+		}
+		buf.WriteRune(c)
+	}
+	buf.WriteByte('"')
+	return buf.String()
+}
+
+func couplingGraphML(pairs []CoupledPair) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	buf.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	buf.WriteString(`  <key id="support" for="edge" attr.name="support" attr.type="int"/>` + "\n")
+	buf.WriteString(`  <key id="confidence" for="edge" attr.name="confidence" attr.type="double"/>` + "\n")
+	buf.WriteString(`  <graph id="coupling" edgedefault="undirected">` + "\n")
+
+	seen := map[string]bool{}
+	for _, p := range pairs {
+		for _, f := range []string{p.FileA, p.FileB} {
+			if !seen[f] {
+				seen[f] = true
+				fmt.Fprintf(&buf, "    <node id=\"%s\"/>\n", xmlEscape(f))
+			}
+		}
+	}
+
+	for i, p := range pairs {
+		confidence := p.ConfidenceAToB
+		if p.ConfidenceBToA > confidence {
+			confidence = p.ConfidenceBToA
+		}
+		fmt.Fprintf(&buf, "    <edge id=\"e%d\" source=\"%s\" target=\"%s\">\n", i, xmlEscape(p.FileA), xmlEscape(p.FileB))
+		fmt.Fprintf(&buf, "      <data key=\"support\">%d</data>\n", p.Support)
+		fmt.Fprintf(&buf, "      <data key=\"confidence\">%g</data>\n", confidence)
+		buf.WriteString("    </edge>\n")
+	}
+
+	buf.WriteString("  </graph>\n</graphml>\n")
+	return buf.Bytes()
+}
+
+func xmlEscape(s string) string {
+	var buf bytes.Buffer
+	xml.EscapeText(&buf, []byte(s))
+	return buf.String()
+}
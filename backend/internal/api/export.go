@@ -0,0 +1,65 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"gitsense"
+	"gitsense/internal/bots"
+	"gitsense/internal/export"
+)
+
+// ----------------------------
+// BULK EXPORT
+// Streams a repo's commits, commit files, file activity or snapshots as CSV
+// or NDJSON: /export/{dataset}?repo=...&format=csv|ndjson
+// ----------------------------
+func ExportHandler(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/export"), "/")
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+	if err := export.Validate(name, format); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	filename := strings.ReplaceAll(repo, "/", "-") + "-" + name + "." + format
+	w.Header().Set("Content-Type", export.ContentType(format))
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	tr.Echo(w)
+
+	// Large exports outlast the server's write timeout; lift it for this
+	// response so the stream isn't cut off part-way
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		fmt.Printf("⚠️  Could not lift the write deadline for export: %v\n", err)
+	}
+
+	// Headers are sent with the first rows, so a failure part-way can
+	// only be logged
+	if n, err := export.Write(w, name, format, repo, tr, detector); err != nil {
+		fmt.Printf("⚠️  Export of %s for %s failed after %d rows: %v\n", name, repo, n, err)
+	}
+}
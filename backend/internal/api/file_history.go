@@ -0,0 +1,268 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"gitsense"
+	"gitsense/internal/bots"
+	"gitsense/internal/db"
+	"gitsense/internal/settings"
+)
+
+// FileCommit is one commit that touched a file, under the name the file had
+// at the time
+type FileCommit struct {
+	SHA          string `json:"sha"`
+	Author       string `json:"author"`
+	Date         string `json:"date"`
+	Message      string `json:"message"`
+	Path         string `json:"path"`
+	Status       string `json:"status"`
+	PreviousPath string `json:"previous_path,omitempty"`
+	Additions    int    `json:"additions"`
+	Deletions    int    `json:"deletions"`
+
+	at time.Time
+}
+
+// StatusTransition is a change in a file's state: active after a commit,
+// then stable and inactive as the thresholds pass without changes, or
+// removed when a commit deletes it
+type StatusTransition struct {
+	Date   string `json:"date"`
+	Status string `json:"status"`
+	SHA    string `json:"sha,omitempty"`
+}
+
+// ----------------------------
+// FILE HISTORY
+// Every stored commit that touched a path, following renames back to the
+// file's earlier names, with author shares, a weekly change series and the
+// file's status transitions
+// ----------------------------
+func GetFileHistory(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	path := strings.Trim(r.URL.Query().Get("path"), "/")
+	if path == "" {
+		http.Error(w, "Path required", 400)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	thresholds, err := settings.GetThresholds(repo)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	history, names, err := followFileHistory(repo, path, detector, tr.To)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+	if len(history) == 0 {
+		http.Error(w, "No commits found for path", 404)
+		return
+	}
+
+	// Transitions need the commits before the range to know the state
+	// it starts in, so they are computed before filtering
+	transitions := []StatusTransition{}
+	for _, t := range fileTransitions(history, thresholds, tr.To) {
+		if d, _ := time.Parse(time.RFC3339, t.Date); tr.Contains(d) {
+			transitions = append(transitions, t)
+		}
+	}
+
+	commits := []FileCommit{}
+	for _, c := range history {
+		if tr.Contains(c.at) {
+			commits = append(commits, c)
+		}
+	}
+
+	type AuthorShare struct {
+		Author      string  `json:"author"`
+		Commits     int     `json:"commits"`
+		Additions   int     `json:"additions"`
+		Deletions   int     `json:"deletions"`
+		CommitShare float64 `json:"commit_share"`
+		ChurnShare  float64 `json:"churn_share"`
+	}
+
+	byAuthor := map[string]*AuthorShare{}
+	weeks := map[string]map[string]int{}
+	totalChurn := 0
+	for _, c := range commits {
+		a := byAuthor[c.Author]
+		if a == nil {
+			a = &AuthorShare{Author: c.Author}
+			byAuthor[c.Author] = a
+		}
+		a.Commits++
+		a.Additions += c.Additions
+		a.Deletions += c.Deletions
+		totalChurn += c.Additions + c.Deletions
+
+		week := periodStart(c.at, "week")
+		if weeks[week] == nil {
+			weeks[week] = map[string]int{}
+		}
+		weeks[week]["commits"]++
+		weeks[week]["additions"] += c.Additions
+		weeks[week]["deletions"] += c.Deletions
+	}
+
+	authors := []AuthorShare{}
+	for _, a := range byAuthor {
+		a.CommitShare = gitsense.Round(float64(a.Commits)/float64(len(commits))*100, 1)
+		if totalChurn > 0 {
+			a.ChurnShare = gitsense.Round(float64(a.Additions+a.Deletions)/float64(totalChurn)*100, 1)
+		}
+		authors = append(authors, *a)
+	}
+	sort.Slice(authors, func(i, j int) bool {
+		if authors[i].Commits != authors[j].Commits {
+			return authors[i].Commits > authors[j].Commits
+		}
+		return authors[i].Author < authors[j].Author
+	})
+
+	// Weekly series including the zero weeks between first and last change
+	perWeek := []map[string]interface{}{}
+	if len(commits) > 0 {
+		first, last := commits[len(commits)-1].at, commits[0].at
+		for wk := weekStartOf(first); !wk.After(last); wk = wk.AddDate(0, 0, 7) {
+			key := wk.Format("2006-01-02")
+			perWeek = append(perWeek, map[string]interface{}{
+				"week":      key,
+				"commits":   weeks[key]["commits"],
+				"additions": weeks[key]["additions"],
+				"deletions": weeks[key]["deletions"],
+			})
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"path":        path,
+		"names":       names,
+		"commits":     commits,
+		"authors":     authors,
+		"weekly":      perWeek,
+		"transitions": transitions,
+		"range":       tr,
+	})
+}
+
+// followFileHistory returns the commits that touched path up to the given
+// time, newest first, and the names the file has had (current first). When a
+// commit renamed the file, the history continues under the previous name
+// from before that commit, like git log --follow.
+func followFileHistory(repo, path string, detector *bots.Detector, until time.Time) ([]FileCommit, []string, error) {
+	var history []FileCommit
+	names := []string{}
+	seen := map[string]bool{}
+
+	name, before := path, until.UTC().Format(time.RFC3339)
+	for name != "" && !seen[name] && len(names) < gitsense.MaxFileRenames {
+		seen[name] = true
+		names = append(names, name)
+
+		rows, err := db.DB.Query(`
+			SELECT c.commit_sha, c.author, c.commit_date, c.message,
+				cf.status, cf.previous_filename, cf.additions, cf.deletions
+			FROM commit_files cf
+			JOIN commits c ON c.commit_sha = cf.commit_sha
+			WHERE cf.repo_name = ? AND cf.file_name = ? AND c.commit_date <= ?
+			ORDER BY c.commit_date DESC, c.commit_sha DESC
+		`, repo, name, before)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		next := ""
+		for rows.Next() {
+			c := FileCommit{Path: name}
+			if err := rows.Scan(&c.SHA, &c.Author, &c.Date, &c.Message,
+				&c.Status, &c.PreviousPath, &c.Additions, &c.Deletions); err != nil {
+				rows.Close()
+				return nil, nil, err
+			}
+			c.at, err = time.Parse(time.RFC3339, c.Date)
+			if err != nil {
+				continue
+			}
+			if !detector.Excludes(c.Author) {
+				history = append(history, c)
+			}
+
+			// Older commits under this name belong to whatever was there
+			// before the file was renamed into it. Renames by excluded
+			// authors are still followed.
+			if c.Status == "renamed" && c.PreviousPath != "" {
+				next = c.PreviousPath
+				before = c.at.Add(-time.Second).UTC().Format(time.RFC3339)
+				break
+			}
+		}
+		rows.Close()
+		name = next
+	}
+	return history, names, nil
+}
+
+// fileTransitions replays a file's commits (newest first) against the
+// thresholds, up to the given time
+func fileTransitions(history []FileCommit, thresholds settings.Thresholds, until time.Time) []StatusTransition {
+	transitions := []StatusTransition{}
+	status := ""
+	add := func(t time.Time, s, sha string) {
+		if s != status {
+			transitions = append(transitions, StatusTransition{Date: t.UTC().Format(time.RFC3339), Status: s, SHA: sha})
+			status = s
+		}
+	}
+
+	for i := len(history) - 1; i >= 0; i-- {
+		c := history[i]
+		if c.Status == "removed" {
+			add(c.at, "removed", c.SHA)
+			continue
+		}
+		add(c.at, "active", c.SHA)
+
+		// States the file decays through before its next change
+		end := until
+		if i > 0 {
+			end = history[i-1].at
+		}
+		if stable := c.at.AddDate(0, 0, thresholds.ActiveDays); stable.Before(end) {
+			add(stable, "stable", "")
+		}
+		if inactive := c.at.AddDate(0, 0, thresholds.StableDays); inactive.Before(end) {
+			add(inactive, "inactive", "")
+		}
+	}
+	return transitions
+}
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"gitsense"
+	"gitsense/internal/db"
+	"gitsense/internal/forecast"
+	"gitsense/internal/scoring"
+)
+
+// ----------------------------
+// ACTIVITY FORECAST
+// Projects activity score and active file count forward from repo_snapshots.
+// Snapshots are resampled to one value per day (last snapshot wins, gaps
+// carry the previous value) before fitting the model.
+// ----------------------------
+func GetActivityForecast(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	horizon, err := gitsense.ValidateIntParam(r, "days", gitsense.DefaultForecastDays, 1, gitsense.MaxForecastDays)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	model := r.URL.Query().Get("model")
+	if model == "" {
+		model = forecast.ModelLinear
+	}
+	if model != forecast.ModelLinear && model != forecast.ModelHolt {
+		http.Error(w, "model must be linear or holt", 400)
+		return
+	}
+
+	// The model is fitted to the snapshots within the range
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	from, to := tr.Bounds()
+
+	rows, err := db.DB.Query(`
+		SELECT DATE(created_at), active_files, active_files + stable_files + inactive_files, activity_score
+		FROM repo_snapshots
+		WHERE repo_name = ?
+		  AND julianday(created_at) BETWEEN julianday(?) AND julianday(?)
+		ORDER BY created_at ASC
+	`, repo, from, to)
+
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+	defer rows.Close()
+
+	type dailyValue struct {
+		active int
+		total  int
+		score  float64
+	}
+	byDay := map[string]dailyValue{}
+	var firstDay, lastDay time.Time
+
+	for rows.Next() {
+		var day string
+		var v dailyValue
+		rows.Scan(&day, &v.active, &v.total, &v.score)
+
+		t, err := time.Parse("2006-01-02", day)
+		if err != nil {
+			continue
+		}
+		if firstDay.IsZero() || t.Before(firstDay) {
+			firstDay = t
+		}
+		if t.After(lastDay) {
+			lastDay = t
+		}
+		byDay[day] = v
+	}
+
+	var history []map[string]interface{}
+	var scores, actives []float64
+	var last dailyValue
+	for d := firstDay; !firstDay.IsZero() && !d.After(lastDay); d = d.AddDate(0, 0, 1) {
+		key := d.Format("2006-01-02")
+		if v, ok := byDay[key]; ok {
+			last = v
+		}
+		scores = append(scores, last.score)
+		actives = append(actives, float64(last.active))
+		history = append(history, map[string]interface{}{
+			"date":   key,
+			"score":  last.score,
+			"active": last.active,
+		})
+	}
+
+	if len(scores) < gitsense.MinForecastPoints {
+		http.Error(w, "Not enough history to forecast", 422)
+		return
+	}
+
+	scoreForecast, err := forecast.Project(model, scores, horizon)
+	if err != nil {
+		http.Error(w, err.Error(), 422)
+		return
+	}
+	forecast.Clamp(scoreForecast, 0, 100)
+
+	activeForecast, err := forecast.Project(model, actives, horizon)
+	if err != nil {
+		http.Error(w, err.Error(), 422)
+		return
+	}
+	// Active files can't exceed the files the repo has at its latest snapshot
+	forecast.Clamp(activeForecast, 0, float64(last.total))
+
+	currentState := scoring.State(scores[len(scores)-1])
+	var warning map[string]interface{}
+
+	var scorePoints, activePoints []map[string]interface{}
+	for i := range scoreForecast {
+		date := lastDay.AddDate(0, 0, i+1).Format("2006-01-02")
+		s := scoreForecast[i]
+		state := scoring.State(s.Value)
+
+		scorePoints = append(scorePoints, map[string]interface{}{
+			"date":  date,
+			"value": gitsense.Round(s.Value, 1),
+			"lower": gitsense.Round(s.Lower, 1),
+			"upper": gitsense.Round(s.Upper, 1),
+			"state": state,
+		})
+
+		a := activeForecast[i]
+		activePoints = append(activePoints, map[string]interface{}{
+			"date":  date,
+			"value": gitsense.Round(a.Value, 1),
+			"lower": gitsense.Round(a.Lower, 1),
+			"upper": gitsense.Round(a.Upper, 1),
+		})
+
+		if warning == nil && currentState != "STABLE" && state == "STABLE" {
+			warning = map[string]interface{}{
+				"date":    date,
+				"state":   state,
+				"message": "Activity score is projected to drop into STABLE by " + date,
+			}
+		}
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"model":                 model,
+		"days":                  horizon,
+		"current_state":         currentState,
+		"history":               history,
+		"score_forecast":        scorePoints,
+		"active_files_forecast": activePoints,
+		"warning":               warning,
+		"range":                 tr,
+	})
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"gitsense"
+	"gitsense/internal/bots"
+	"gitsense/internal/db"
+)
+
+// Heatmap rows run Monday to Sunday
+var heatmapWeekdays = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// ----------------------------
+// COMMIT TIME HEATMAP
+// Weekday x hour-of-day commit counts. Without tz each commit is placed in
+// its author's original timezone; with tz all commits are normalized to it.
+// ----------------------------
+func GetCommitHeatmap(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	loc, err := gitsense.ValidateTimezoneParam(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT author, commit_date, tz_offset_minutes
+		FROM commits
+		WHERE repo_name = ?
+	`, repo)
+
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+	defer rows.Close()
+
+	matrix := make([][]int, 7)
+	for i := range matrix {
+		matrix[i] = make([]int, 24)
+	}
+	total, unknownOffsets := 0, 0
+
+	for rows.Next() {
+		var author, commitDate string
+		var offset sql.NullInt64
+
+		if err := rows.Scan(&author, &commitDate, &offset); err != nil {
+			http.Error(w, "DB error", 500)
+			return
+		}
+		if detector.Excludes(author) {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, commitDate)
+		if err != nil || !tr.Contains(t) {
+			continue
+		}
+
+		switch {
+		case loc != nil:
+			t = t.In(loc)
+		case offset.Valid:
+			t = t.In(time.FixedZone("", int(offset.Int64)*60))
+		default:
+			t = t.UTC()
+			unknownOffsets++
+		}
+
+		day := (int(t.Weekday()) + 6) % 7 // Monday first
+		matrix[day][t.Hour()]++
+		total++
+	}
+
+	mode := "author_local"
+	if loc != nil {
+		mode = loc.String()
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"timezone":        mode,
+		"weekdays":        heatmapWeekdays,
+		"matrix":          matrix,
+		"total":           total,
+		"unknown_offsets": unknownOffsets,
+		"range":           tr,
+	})
+}
@@ -0,0 +1,141 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	"gitsense"
+	"gitsense/internal/activity"
+	"gitsense/internal/bots"
+)
+
+// ----------------------------
+// HOTSPOTS
+// Ranks files by recent change frequency multiplied by file size,
+// i.e. large files that keep changing
+// ----------------------------
+func GetHotspots(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	days, err := gitsense.ValidateIntParam(r, "days", gitsense.DefaultHotspotDays, 1, gitsense.MaxThresholdDays)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	limit, err := gitsense.ValidateIntParam(r, "limit", gitsense.DefaultHotspotLimit, 1, gitsense.MaxHotspotLimit)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	// from/to take precedence over days
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+	bounded := tr.Bounded()
+	tr = tr.WithDefaultDays(days)
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	files, err := activity.LoadFileStats(repo, detector)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	changes, err := loadFileChanges(repo, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	type recentActivity struct {
+		shas  map[string]bool
+		churn int
+	}
+	recent := map[string]*recentActivity{}
+	for _, fc := range changes {
+		a := recent[fc.FileName]
+		if a == nil {
+			a = &recentActivity{shas: map[string]bool{}}
+			recent[fc.FileName] = a
+		}
+		a.shas[fc.SHA] = true
+		a.churn += fc.Additions + fc.Deletions
+	}
+
+	type Hotspot struct {
+		Name          string  `json:"name"`
+		RecentCommits int     `json:"recent_commits"`
+		RecentChurn   int     `json:"recent_churn"`
+		TotalCommits  int     `json:"total_commits"`
+		SizeBytes     int64   `json:"size_bytes"`
+		LastModified  string  `json:"last_modified"`
+		Score         float64 `json:"score"`
+		RelativeScore float64 `json:"relative_score"`
+	}
+
+	hotspots := []Hotspot{}
+	maxScore := 0.0
+
+	for _, f := range files {
+		a := recent[f.Name]
+		if a == nil || f.SizeBytes <= 0 {
+			continue
+		}
+
+		h := Hotspot{
+			Name:          f.Name,
+			RecentCommits: len(a.shas),
+			RecentChurn:   a.churn,
+			TotalCommits:  f.CommitCount,
+			SizeBytes:     f.SizeBytes,
+			LastModified:  f.LastModified,
+		}
+		h.Score = float64(h.RecentCommits) * float64(h.SizeBytes)
+		if h.Score > maxScore {
+			maxScore = h.Score
+		}
+		hotspots = append(hotspots, h)
+	}
+
+	sort.Slice(hotspots, func(i, j int) bool {
+		if hotspots[i].Score != hotspots[j].Score {
+			return hotspots[i].Score > hotspots[j].Score
+		}
+		return hotspots[i].Name < hotspots[j].Name
+	})
+	if len(hotspots) > limit {
+		hotspots = hotspots[:limit]
+	}
+
+	// Relative score (0-100) against the hottest file, for dashboard scaling
+	for i := range hotspots {
+		if maxScore > 0 {
+			hotspots[i].RelativeScore = gitsense.Round(hotspots[i].Score/maxScore*100, 1)
+		}
+	}
+
+	response := map[string]interface{}{
+		"hotspots": hotspots,
+		"range":    tr,
+	}
+	// days only describes the window when from didn't replace it
+	if !bounded {
+		response["days"] = days
+	}
+	json.NewEncoder(w).Encode(response)
+}
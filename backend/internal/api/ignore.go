@@ -0,0 +1,184 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gitsense"
+	"gitsense/internal/auth"
+	"gitsense/internal/db"
+	"gitsense/internal/ignore"
+	"gitsense/internal/repos"
+	syncer "gitsense/internal/sync"
+)
+
+// ----------------------------
+// IGNORE RULES
+// GET lists the repo's rules, POST replaces the user-defined rules
+// ----------------------------
+func IgnoreRulesHandler(w http.ResponseWriter, r *http.Request) {
+	gitsense.SetCORSHeaders(w)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	repo, err := gitsense.ValidateRepoParam(r)
+	if err != nil {
+		gitsense.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		// read-only, nothing to do before listing
+
+	case http.MethodPost:
+		_, userID, err := auth.Authenticate(r)
+		if err != nil {
+			gitsense.SendJSONError(w, "Invalid or expired session", http.StatusUnauthorized)
+			return
+		}
+
+		tracked, err := repos.IsTracked(userID, repo)
+		if err != nil {
+			gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		if !tracked {
+			gitsense.SendJSONError(w, "Repo has not been synced by this user", http.StatusForbidden)
+			return
+		}
+
+		var body struct {
+			Patterns []string `json:"patterns"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			gitsense.SendJSONError(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if len(body.Patterns) > gitsense.MaxIgnorePatterns {
+			gitsense.SendJSONError(w, "Too many patterns", http.StatusBadRequest)
+			return
+		}
+		if _, err := ignore.NewMatcher(body.Patterns); err != nil {
+			gitsense.SendJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, err := ignore.ReplaceRules(repo, ignore.SourceUser, body.Patterns); err != nil {
+			gitsense.SendJSONError(w, "Failed to save ignore rules", http.StatusInternalServerError)
+			return
+		}
+
+		// Snapshot counts depend on which files are ignored
+		if _, err := syncer.RecomputeSnapshots(repo); err != nil {
+			gitsense.SendJSONError(w, "Failed to recompute snapshots", http.StatusInternalServerError)
+			return
+		}
+
+	default:
+		gitsense.SendJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rules, err := ignore.GetRules(repo)
+	if err != nil {
+		gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"repo":  repo,
+		"rules": rules,
+	})
+}
+
+// ----------------------------
+// IGNORE RULES PREVIEW
+// Shows which tracked files a rule set would exclude. Uses the "pattern"
+// query params when given, otherwise the repo's stored rules.
+// ----------------------------
+func PreviewIgnoreRules(w http.ResponseWriter, r *http.Request) {
+	gitsense.SetCORSHeaders(w)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	repo, err := gitsense.ValidateRepoParam(r)
+	if err != nil {
+		gitsense.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	patterns := r.URL.Query()["pattern"]
+	if len(patterns) > gitsense.MaxIgnorePatterns {
+		gitsense.SendJSONError(w, "Too many patterns", http.StatusBadRequest)
+		return
+	}
+
+	var matcher *ignore.Matcher
+	if len(patterns) > 0 {
+		matcher, err = ignore.NewMatcher(patterns)
+		if err != nil {
+			gitsense.SendJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		matcher, err = ignore.ForRepo(repo)
+		if err != nil {
+			gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		rules, _ := ignore.GetRules(repo)
+		for _, rule := range rules {
+			patterns = append(patterns, rule.Pattern)
+		}
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT file_name, commit_count
+		FROM file_activity
+		WHERE repo_name = ?
+		ORDER BY file_name ASC
+	`, repo)
+	if err != nil {
+		gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	type PreviewFile struct {
+		Name        string `json:"name"`
+		CommitCount int    `json:"commit_count"`
+	}
+
+	excluded := []PreviewFile{}
+	total, excludedCommits := 0, 0
+
+	for rows.Next() {
+		var f PreviewFile
+		if err := rows.Scan(&f.Name, &f.CommitCount); err != nil {
+			gitsense.SendJSONError(w, "Failed to scan file data", http.StatusInternalServerError)
+			return
+		}
+		total++
+		if matcher.Ignored(f.Name) {
+			excluded = append(excluded, f)
+			excludedCommits += f.CommitCount
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"repo":             repo,
+		"patterns":         patterns,
+		"total_files":      total,
+		"excluded_count":   len(excluded),
+		"excluded_commits": excludedCommits,
+		"excluded":         excluded,
+	})
+}
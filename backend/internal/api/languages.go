@@ -0,0 +1,181 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"gitsense"
+	"gitsense/internal/activity"
+	"gitsense/internal/bots"
+	"gitsense/internal/languages"
+	"gitsense/internal/settings"
+)
+
+// ----------------------------
+// LANGUAGE BREAKDOWN
+// Per-language file counts, commit share and activity split,
+// plus a per-period series of commits touching each language
+// ----------------------------
+func GetLanguageBreakdown(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	interval := r.URL.Query().Get("interval")
+	if interval == "" {
+		interval = "week"
+	}
+	if interval != "week" && interval != "month" {
+		http.Error(w, "interval must be week or month", 400)
+		return
+	}
+
+	thresholds, err := settings.GetThresholds(repo)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	files, err := activity.LoadFileStatsInRange(repo, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	type LanguageStats struct {
+		Language      string  `json:"language"`
+		Files         int     `json:"files"`
+		Commits       int     `json:"commits"`
+		CommitShare   float64 `json:"commit_share"`
+		ActiveFiles   int     `json:"active_files"`
+		StableFiles   int     `json:"stable_files"`
+		InactiveFiles int     `json:"inactive_files"`
+	}
+
+	stats := map[string]*LanguageStats{}
+	totalCommits := 0
+
+	for _, f := range files {
+		lang := languages.Detect(f.Name)
+		s, ok := stats[lang]
+		if !ok {
+			s = &LanguageStats{Language: lang}
+			stats[lang] = s
+		}
+
+		t, _ := time.Parse(time.RFC3339, f.LastModified)
+		switch thresholds.Classify(tr.To.Sub(t).Hours() / 24) {
+		case "active":
+			s.ActiveFiles++
+		case "stable":
+			s.StableFiles++
+		default:
+			s.InactiveFiles++
+		}
+
+		s.Files++
+		s.Commits += f.CommitCount
+		totalCommits += f.CommitCount
+	}
+
+	breakdown := []LanguageStats{}
+	for _, s := range stats {
+		if totalCommits > 0 {
+			s.CommitShare = gitsense.Round(float64(s.Commits)/float64(totalCommits)*100, 1)
+		}
+		breakdown = append(breakdown, *s)
+	}
+	sort.Slice(breakdown, func(i, j int) bool {
+		if breakdown[i].Commits != breakdown[j].Commits {
+			return breakdown[i].Commits > breakdown[j].Commits
+		}
+		return breakdown[i].Language < breakdown[j].Language
+	})
+
+	timeline, err := languageTimeline(repo, interval, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"languages": breakdown,
+		"timeline":  timeline,
+		"interval":  interval,
+		"range":     tr,
+	})
+}
+
+// languageTimeline counts distinct commits per language per week or month
+func languageTimeline(repo, interval string, detector *bots.Detector, tr gitsense.TimeRange) ([]map[string]interface{}, error) {
+	changes, err := loadFileChanges(repo, detector, tr)
+	if err != nil {
+		return nil, err
+	}
+
+	// period -> language -> set of commit SHAs
+	periods := map[string]map[string]map[string]bool{}
+
+	for _, fc := range changes {
+		t, err := time.Parse(time.RFC3339, fc.CommitDate)
+		if err != nil {
+			continue
+		}
+		period := periodStart(t, interval)
+
+		if periods[period] == nil {
+			periods[period] = map[string]map[string]bool{}
+		}
+		lang := languages.Detect(fc.FileName)
+		if periods[period][lang] == nil {
+			periods[period][lang] = map[string]bool{}
+		}
+		periods[period][lang][fc.SHA] = true
+	}
+
+	keys := make([]string, 0, len(periods))
+	for k := range periods {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	timeline := []map[string]interface{}{}
+	for _, k := range keys {
+		counts := map[string]int{}
+		for lang, shas := range periods[k] {
+			counts[lang] = len(shas)
+		}
+		timeline = append(timeline, map[string]interface{}{
+			"period":  k,
+			"commits": counts,
+		})
+	}
+	return timeline, nil
+}
+
+// periodStart returns the first day (YYYY-MM-DD) of the week (Monday) or month containing t
+func periodStart(t time.Time, interval string) string {
+	t = t.UTC()
+	if interval == "month" {
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	}
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+}
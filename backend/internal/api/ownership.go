@@ -0,0 +1,353 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+
+	"gitsense"
+	"gitsense/internal/bots"
+)
+
+type OwnerShare struct {
+	Author string  `json:"author"`
+	Value  int     `json:"value"`
+	Share  float64 `json:"share"`
+}
+
+type OwnershipReport struct {
+	Path      string       `json:"path"`
+	Total     int          `json:"total"`
+	BusFactor int          `json:"bus_factor"`
+	Owners    []OwnerShare `json:"owners"`
+}
+
+// ownershipTally accumulates per-author commits or churn for one path
+type ownershipTally struct {
+	metric string
+	values map[string]int
+	shas   map[string]map[string]bool // author -> commit SHAs, for the commits metric
+}
+
+func newOwnershipTally(metric string) *ownershipTally {
+	return &ownershipTally{
+		metric: metric,
+		values: map[string]int{},
+		shas:   map[string]map[string]bool{},
+	}
+}
+
+func (t *ownershipTally) add(fc fileChange) {
+	if t.metric == "churn" {
+		t.values[fc.Author] += fc.Additions + fc.Deletions
+		return
+	}
+	if t.shas[fc.Author] == nil {
+		t.shas[fc.Author] = map[string]bool{}
+	}
+	if !t.shas[fc.Author][fc.SHA] {
+		t.shas[fc.Author][fc.SHA] = true
+		t.values[fc.Author]++
+	}
+}
+
+// report converts the tally into owner shares and a bus factor: the smallest
+// number of authors who together hold more than BusFactorShare of the work
+func (t *ownershipTally) report(path string) OwnershipReport {
+	rep := OwnershipReport{Path: path, Owners: []OwnerShare{}}
+	for author, v := range t.values {
+		rep.Total += v
+		rep.Owners = append(rep.Owners, OwnerShare{Author: author, Value: v})
+	}
+	sort.Slice(rep.Owners, func(i, j int) bool {
+		if rep.Owners[i].Value != rep.Owners[j].Value {
+			return rep.Owners[i].Value > rep.Owners[j].Value
+		}
+		return rep.Owners[i].Author < rep.Owners[j].Author
+	})
+
+	if rep.Total == 0 {
+		return rep
+	}
+
+	cumulative := 0.0
+	for i := range rep.Owners {
+		share := float64(rep.Owners[i].Value) / float64(rep.Total)
+		rep.Owners[i].Share = gitsense.Round(share*100, 1)
+
+		if cumulative <= gitsense.BusFactorShare {
+			cumulative += share
+			rep.BusFactor++
+		}
+	}
+	return rep
+}
+
+func ownershipMetric(r *http.Request) (string, bool) {
+	metric := r.URL.Query().Get("metric")
+	if metric == "" {
+		metric = "commits"
+	}
+	return metric, metric == "commits" || metric == "churn"
+}
+
+// directoryOf returns the directory of a path truncated to depth segments ("" for the repo root)
+func directoryOf(fileName string, depth int) string {
+	segs := strings.Split(fileName, "/")
+	segs = segs[:len(segs)-1]
+	if len(segs) > depth {
+		segs = segs[:depth]
+	}
+	return strings.Join(segs, "/")
+}
+
+// ----------------------------
+// OWNERSHIP
+// Author shares and bus factor for a file, a directory (path prefix)
+// or the whole repo when no path is given
+// ----------------------------
+func GetOwnership(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	metric, ok := ownershipMetric(r)
+	if !ok {
+		http.Error(w, "metric must be commits or churn", 400)
+		return
+	}
+
+	path := strings.Trim(r.URL.Query().Get("path"), "/")
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	changes, err := loadFileChanges(repo, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	tally := newOwnershipTally(metric)
+	for _, fc := range changes {
+		if path == "" || fc.FileName == path || strings.HasPrefix(fc.FileName, path+"/") {
+			tally.add(fc)
+		}
+	}
+
+	rep := tally.report(path)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"metric":     metric,
+		"path":       rep.Path,
+		"total":      rep.Total,
+		"bus_factor": rep.BusFactor,
+		"owners":     rep.Owners,
+		"range":      tr,
+	})
+}
+
+// ----------------------------
+// OWNERSHIP BREAKDOWN
+// Bus factor for every file (level=file) or directory (level=directory),
+// lowest bus factor first
+// ----------------------------
+func GetOwnershipBreakdown(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	metric, ok := ownershipMetric(r)
+	if !ok {
+		http.Error(w, "metric must be commits or churn", 400)
+		return
+	}
+
+	level := r.URL.Query().Get("level")
+	if level == "" {
+		level = "directory"
+	}
+	if level != "file" && level != "directory" {
+		http.Error(w, "level must be file or directory", 400)
+		return
+	}
+
+	depth, err := gitsense.ValidateIntParam(r, "depth", gitsense.DefaultTreeDepth, 1, gitsense.MaxTreeDepth)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	limit, err := gitsense.ValidateIntParam(r, "limit", gitsense.DefaultChurnLimit, 1, gitsense.MaxChurnLimit)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	changes, err := loadFileChanges(repo, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	tallies := map[string]*ownershipTally{}
+	for _, fc := range changes {
+		key := fc.FileName
+		if level == "directory" {
+			key = directoryOf(fc.FileName, depth)
+		}
+		if tallies[key] == nil {
+			tallies[key] = newOwnershipTally(metric)
+		}
+		tallies[key].add(fc)
+	}
+
+	type OwnershipSummary struct {
+		Path      string  `json:"path"`
+		Total     int     `json:"total"`
+		BusFactor int     `json:"bus_factor"`
+		Authors   int     `json:"authors"`
+		TopOwner  string  `json:"top_owner"`
+		TopShare  float64 `json:"top_share"`
+	}
+
+	summaries := []OwnershipSummary{}
+	for key, tally := range tallies {
+		rep := tally.report(key)
+		s := OwnershipSummary{Path: key, Total: rep.Total, BusFactor: rep.BusFactor, Authors: len(rep.Owners)}
+		if len(rep.Owners) > 0 {
+			s.TopOwner = rep.Owners[0].Author
+			s.TopShare = rep.Owners[0].Share
+		}
+		summaries = append(summaries, s)
+	}
+
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].BusFactor != summaries[j].BusFactor {
+			return summaries[i].BusFactor < summaries[j].BusFactor
+		}
+		if summaries[i].Total != summaries[j].Total {
+			return summaries[i].Total > summaries[j].Total
+		}
+		return summaries[i].Path < summaries[j].Path
+	})
+	if len(summaries) > limit {
+		summaries = summaries[:limit]
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"metric":  metric,
+		"level":   level,
+		"entries": summaries,
+		"range":   tr,
+	})
+}
+
+// ----------------------------
+// SINGLE-OWNER FILES
+// Files that only one author has ever changed, grouped by owner
+// ----------------------------
+func GetSingleOwnerFiles(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	changes, err := loadFileChanges(repo, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	authorsByFile := map[string]map[string]bool{}
+	for _, fc := range changes {
+		if authorsByFile[fc.FileName] == nil {
+			authorsByFile[fc.FileName] = map[string]bool{}
+		}
+		authorsByFile[fc.FileName][fc.Author] = true
+	}
+
+	byOwner := map[string][]string{}
+	singleOwned := 0
+	for file, authors := range authorsByFile {
+		if len(authors) != 1 {
+			continue
+		}
+		for author := range authors {
+			byOwner[author] = append(byOwner[author], file)
+		}
+		singleOwned++
+	}
+
+	type OwnerFiles struct {
+		Author string   `json:"author"`
+		Count  int      `json:"count"`
+		Files  []string `json:"files"`
+	}
+
+	owners := []OwnerFiles{}
+	for author, files := range byOwner {
+		sort.Strings(files)
+		owners = append(owners, OwnerFiles{Author: author, Count: len(files), Files: files})
+	}
+	sort.Slice(owners, func(i, j int) bool {
+		if owners[i].Count != owners[j].Count {
+			return owners[i].Count > owners[j].Count
+		}
+		return owners[i].Author < owners[j].Author
+	})
+
+	share := 0.0
+	if len(authorsByFile) > 0 {
+		share = gitsense.Round(float64(singleOwned)/float64(len(authorsByFile))*100, 1)
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"total_files":        len(authorsByFile),
+		"single_owner_files": singleOwned,
+		"single_owner_share": share,
+		"owners":             owners,
+		"range":              tr,
+	})
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gitsense"
+	"gitsense/internal/auth"
+	"gitsense/internal/db"
+	githubapi "gitsense/internal/github"
+	"gitsense/internal/portfolio"
+	"gitsense/internal/repos"
+)
+
+// portfolioSorts maps the sort parameter to a comparison of two entries
+var portfolioSorts = map[string]func(a, b portfolio.Entry) bool{
+	"score":        func(a, b portfolio.Entry) bool { return a.ActivityScore < b.ActivityScore },
+	"score_change": func(a, b portfolio.Entry) bool { return a.ScoreChange < b.ScoreChange },
+	"velocity":     func(a, b portfolio.Entry) bool { return a.Velocity < b.Velocity },
+	"commits":      func(a, b portfolio.Entry) bool { return a.Commits30d < b.Commits30d },
+	"last_synced":  func(a, b portfolio.Entry) bool { return a.LastSynced < b.LastSynced },
+	"name":         func(a, b portfolio.Entry) bool { return a.Repo < b.Repo },
+}
+
+// ----------------------------
+// PORTFOLIO DASHBOARD
+// Precomputed per-repo aggregates for every repo the user has synced, or
+// every synced repo of a GitHub user/org the user belongs to (org param)
+// ----------------------------
+func GetPortfolioDashboard(w http.ResponseWriter, r *http.Request) {
+	gitsense.SetCORSHeaders(w)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	q := r.URL.Query()
+
+	sortBy := q.Get("sort")
+	if sortBy == "" {
+		sortBy = "score"
+	}
+	less, ok := portfolioSorts[sortBy]
+	if !ok {
+		http.Error(w, "sort must be score, score_change, velocity, commits, last_synced or name", 400)
+		return
+	}
+
+	order := q.Get("order")
+	if order == "" {
+		order = "desc"
+		if sortBy == "name" {
+			order = "asc"
+		}
+	}
+	if order != "asc" && order != "desc" {
+		http.Error(w, "order must be asc or desc", 400)
+		return
+	}
+
+	// States are matched case-insensitively, with _ standing in for spaces
+	state := strings.ToUpper(strings.ReplaceAll(q.Get("state"), "_", " "))
+
+	trend := q.Get("trend")
+	if trend != "" && trend != portfolio.TrendUp && trend != portfolio.TrendDown && trend != portfolio.TrendFlat {
+		http.Error(w, "trend must be up, down or flat", 400)
+		return
+	}
+
+	minScore := 0.0
+	if v := q.Get("min_score"); v != "" {
+		var err error
+		minScore, err = strconv.ParseFloat(v, 64)
+		if err != nil || minScore < 0 || minScore > 100 {
+			http.Error(w, "min_score must be a number between 0 and 100", 400)
+			return
+		}
+	}
+
+	githubToken, userID, err := auth.Authenticate(r)
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var entries []portfolio.Entry
+	if org := q.Get("org"); org != "" {
+		allowed, err := canViewOwner(userID, githubToken, org)
+		if err != nil {
+			http.Error(w, "GitHub error", http.StatusBadGateway)
+			return
+		}
+		if !allowed {
+			http.Error(w, "Not a member of this organization", http.StatusForbidden)
+			return
+		}
+		entries, err = portfolio.ForOwner(org)
+		if err != nil {
+			http.Error(w, "DB error", 500)
+			return
+		}
+	} else {
+		repoNames, err := repos.TrackedRepos(userID)
+		if err != nil {
+			http.Error(w, "DB error", 500)
+			return
+		}
+		entries, err = portfolio.ForRepos(repoNames)
+		if err != nil {
+			http.Error(w, "DB error", 500)
+			return
+		}
+	}
+
+	filtered := []portfolio.Entry{}
+	for _, e := range entries {
+		if state != "" && e.ProjectState != state {
+			continue
+		}
+		if trend != "" && e.Trend != trend {
+			continue
+		}
+		if e.ActivityScore < minScore {
+			continue
+		}
+		filtered = append(filtered, e)
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		a, b := filtered[i], filtered[j]
+		if less(a, b) == less(b, a) {
+			return a.Repo < b.Repo // ties
+		}
+		if order == "desc" {
+			return less(b, a)
+		}
+		return less(a, b)
+	})
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"repos": filtered,
+		"count": len(filtered),
+		"sort":  sortBy,
+		"order": order,
+	})
+}
+
+// canViewOwner allows a user to see an owner's repos when the owner is the
+// user's own GitHub account or an org they are an active member of
+func canViewOwner(userID int, githubToken, owner string) (bool, error) {
+	var username string
+	db.DB.QueryRow(`SELECT github_username FROM users WHERE id = ?`, userID).Scan(&username)
+	if username != "" && strings.EqualFold(username, owner) {
+		return true, nil
+	}
+	return githubapi.IsOrgMember(owner, githubToken)
+}
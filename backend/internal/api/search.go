@@ -0,0 +1,94 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"gitsense"
+	"gitsense/internal/auth"
+	"gitsense/internal/bots"
+	"gitsense/internal/repos"
+	"gitsense/internal/search"
+)
+
+// ----------------------------
+// COMMIT SEARCH
+// Full-text search over commit messages, authors and touched paths, in a
+// repo (repo param) or across every repo the authenticated user has synced
+// ----------------------------
+func GetCommitSearch(w http.ResponseWriter, r *http.Request) {
+	gitsense.SetCORSHeaders(w)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	text := strings.TrimSpace(r.URL.Query().Get("q"))
+	if text == "" {
+		http.Error(w, "Query required", 400)
+		return
+	}
+	if _, err := search.MatchExpression(text); err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	limit, err := gitsense.ValidateIntParam(r, "limit", gitsense.DefaultSearchLimit, 1, gitsense.MaxSearchLimit)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	repoNames := []string{}
+	if repo := r.URL.Query().Get("repo"); repo != "" {
+		repoNames = append(repoNames, repo)
+	} else {
+		_, userID, err := auth.Authenticate(r)
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		repoNames, err = repos.TrackedRepos(userID)
+		if err != nil {
+			http.Error(w, "DB error", 500)
+			return
+		}
+	}
+
+	detectors := map[string]*bots.Detector{}
+	for _, repo := range repoNames {
+		detectors[repo], err = bots.ForRequest(r, repo)
+		if err != nil {
+			http.Error(w, err.Error(), 400)
+			return
+		}
+	}
+
+	results, total, err := search.Run(search.Query{
+		Text:      text,
+		Repos:     repoNames,
+		Author:    r.URL.Query().Get("author"),
+		Range:     tr,
+		Limit:     limit,
+		Detectors: detectors,
+	})
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"query":   text,
+		"results": results,
+		"total":   total,
+		"range":   tr,
+	})
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"gitsense"
+	"gitsense/internal/auth"
+	"gitsense/internal/repos"
+	"gitsense/internal/scoring"
+	"gitsense/internal/settings"
+	syncer "gitsense/internal/sync"
+)
+
+// ----------------------------
+// REPO SETTINGS
+// GET returns the repo's thresholds, bot handling and score model, POST
+// updates them and recomputes snapshots
+// ----------------------------
+func RepoSettingsHandler(w http.ResponseWriter, r *http.Request) {
+	gitsense.SetCORSHeaders(w)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	repo, err := gitsense.ValidateRepoParam(r)
+	if err != nil {
+		gitsense.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		thresholds, err := settings.GetThresholds(repo)
+		if err != nil {
+			gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		botSettings, err := settings.GetBotSettings(repo)
+		if err != nil {
+			gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		scoreModel, err := settings.GetScoreModel(repo)
+		if err != nil {
+			gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		writeSettings(w, repo, thresholds, botSettings, scoreModel, 0)
+
+	case http.MethodPost:
+		_, userID, err := auth.Authenticate(r)
+		if err != nil {
+			gitsense.SendJSONError(w, "Invalid or expired session", http.StatusUnauthorized)
+			return
+		}
+
+		tracked, err := repos.IsTracked(userID, repo)
+		if err != nil {
+			gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		if !tracked {
+			gitsense.SendJSONError(w, "Repo has not been synced by this user", http.StatusForbidden)
+			return
+		}
+
+		// Fields missing from the body keep their current values
+		var body struct {
+			settings.Thresholds
+			settings.BotSettings
+			ScoreModel string `json:"score_model"`
+		}
+		body.Thresholds, err = settings.GetThresholds(repo)
+		if err != nil {
+			gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		body.BotSettings, err = settings.GetBotSettings(repo)
+		if err != nil {
+			gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		body.ScoreModel, err = settings.GetScoreModel(repo)
+		if err != nil {
+			gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+			return
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			gitsense.SendJSONError(w, "Invalid JSON body", http.StatusBadRequest)
+			return
+		}
+		if err := body.Thresholds.Validate(); err != nil {
+			gitsense.SendJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := body.BotSettings.Validate(); err != nil {
+			gitsense.SendJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if _, err := scoring.Get(body.ScoreModel); err != nil {
+			gitsense.SendJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := settings.SaveThresholds(repo, body.Thresholds); err != nil {
+			gitsense.SendJSONError(w, "Failed to save settings", http.StatusInternalServerError)
+			return
+		}
+		if err := settings.SaveBotSettings(repo, body.BotSettings); err != nil {
+			gitsense.SendJSONError(w, "Failed to save settings", http.StatusInternalServerError)
+			return
+		}
+		if err := settings.SaveScoreModel(repo, body.ScoreModel); err != nil {
+			gitsense.SendJSONError(w, "Failed to save settings", http.StatusInternalServerError)
+			return
+		}
+
+		recomputed, err := syncer.RecomputeSnapshots(repo)
+		if err != nil {
+			gitsense.SendJSONError(w, "Failed to recompute snapshots", http.StatusInternalServerError)
+			return
+		}
+		writeSettings(w, repo, body.Thresholds, body.BotSettings, body.ScoreModel, recomputed)
+
+	default:
+		gitsense.SendJSONError(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func writeSettings(w http.ResponseWriter, repo string, thresholds settings.Thresholds, botSettings settings.BotSettings, scoreModel string, recomputed int) {
+	response := map[string]interface{}{
+		"repo":        repo,
+		"thresholds":  thresholds,
+		"bots":        botSettings,
+		"score_model": scoreModel,
+	}
+	if recomputed > 0 {
+		response["recomputed_snapshots"] = recomputed
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// ----------------------------
+// SCORE MODELS
+// Lists the health scoring models a repo can select
+// ----------------------------
+func GetScoreModels(w http.ResponseWriter, r *http.Request) {
+	var list []map[string]string
+	for _, name := range scoring.Names() {
+		m, _ := scoring.Get(name)
+		list = append(list, map[string]string{
+			"name":        m.Name(),
+			"description": m.Description(),
+		})
+	}
+
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"default": settings.DefaultScoreModel,
+		"models":  list,
+	})
+}
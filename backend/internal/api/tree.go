@@ -0,0 +1,221 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"gitsense"
+	"gitsense/internal/activity"
+	"gitsense/internal/bots"
+	"gitsense/internal/settings"
+)
+
+type ContributorShare struct {
+	Author  string `json:"author"`
+	Commits int    `json:"commits"`
+}
+
+type DirectoryNode struct {
+	Path            string             `json:"path"`
+	Name            string             `json:"name"`
+	Files           int                `json:"files"`
+	Commits         int                `json:"commits"`
+	ActiveFiles     int                `json:"active_files"`
+	StableFiles     int                `json:"stable_files"`
+	InactiveFiles   int                `json:"inactive_files"`
+	LastModified    string             `json:"last_modified"`
+	TopContributors []ContributorShare `json:"top_contributors"`
+	Children        []*DirectoryNode   `json:"children,omitempty"`
+
+	children map[string]*DirectoryNode
+	authors  map[string]map[string]bool // author -> set of commit SHAs
+}
+
+func newDirectoryNode(path, name string) *DirectoryNode {
+	return &DirectoryNode{
+		Path:     path,
+		Name:     name,
+		children: map[string]*DirectoryNode{},
+		authors:  map[string]map[string]bool{},
+	}
+}
+
+// ----------------------------
+// FILE TREE (directory rollups)
+// Aggregates file_activity by directory under an optional prefix,
+// down to the requested depth
+// ----------------------------
+func GetFileTree(w http.ResponseWriter, r *http.Request) {
+	repo := r.URL.Query().Get("repo")
+
+	if repo == "" {
+		http.Error(w, "Repo required", 400)
+		return
+	}
+
+	depth, err := gitsense.ValidateIntParam(r, "depth", gitsense.DefaultTreeDepth, 1, gitsense.MaxTreeDepth)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	prefix := strings.Trim(r.URL.Query().Get("path"), "/")
+
+	thresholds, err := settings.GetThresholds(repo)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	tr, err := gitsense.ValidateTimeRangeParams(r)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	files, err := activity.LoadFileStatsInRange(repo, detector, tr)
+	if err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	rootName := repo
+	if prefix != "" {
+		rootName = prefix
+	}
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		rootName = prefix[i+1:]
+	}
+	root := newDirectoryNode(prefix, rootName)
+
+	for _, f := range files {
+		nodes := treePath(root, prefix, f.Name, depth)
+		if nodes == nil {
+			continue
+		}
+
+		t, _ := time.Parse(time.RFC3339, f.LastModified)
+		status := thresholds.Classify(tr.To.Sub(t).Hours() / 24)
+
+		for _, n := range nodes {
+			n.Files++
+			switch status {
+			case "active":
+				n.ActiveFiles++
+			case "stable":
+				n.StableFiles++
+			default:
+				n.InactiveFiles++
+			}
+			if f.LastModified > n.LastModified {
+				n.LastModified = f.LastModified
+			}
+		}
+	}
+
+	if err := addTreeContributors(root, repo, prefix, depth, detector, tr); err != nil {
+		http.Error(w, "DB error", 500)
+		return
+	}
+
+	finalizeTree(root)
+
+	tr.Echo(w)
+	json.NewEncoder(w).Encode(root)
+}
+
+// treePath returns the nodes (root first) that a file rolls up into,
+// creating directory nodes as needed. Files outside the prefix return nil.
+func treePath(root *DirectoryNode, prefix, fileName string, depth int) []*DirectoryNode {
+	rel := fileName
+	if prefix != "" {
+		if !strings.HasPrefix(fileName, prefix+"/") {
+			return nil
+		}
+		rel = strings.TrimPrefix(fileName, prefix+"/")
+	}
+
+	dirs := strings.Split(rel, "/")
+	dirs = dirs[:len(dirs)-1] // drop the file name
+	if len(dirs) > depth {
+		dirs = dirs[:depth]
+	}
+
+	nodes := []*DirectoryNode{root}
+	current := root
+	for _, dir := range dirs {
+		child, ok := current.children[dir]
+		if !ok {
+			childPath := dir
+			if current.Path != "" {
+				childPath = current.Path + "/" + dir
+			}
+			child = newDirectoryNode(childPath, dir)
+			current.children[dir] = child
+		}
+		nodes = append(nodes, child)
+		current = child
+	}
+	return nodes
+}
+
+// addTreeContributors counts distinct commits per author for every node,
+// using the per-commit file lists recorded during sync
+func addTreeContributors(root *DirectoryNode, repo, prefix string, depth int, detector *bots.Detector, tr gitsense.TimeRange) error {
+	changes, err := loadFileChanges(repo, detector, tr)
+	if err != nil {
+		return err
+	}
+
+	for _, fc := range changes {
+		for _, n := range treePath(root, prefix, fc.FileName, depth) {
+			if n.authors[fc.Author] == nil {
+				n.authors[fc.Author] = map[string]bool{}
+			}
+			n.authors[fc.Author][fc.SHA] = true
+		}
+	}
+	return nil
+}
+
+// finalizeTree counts each node's distinct commits, sorts children by them
+// and trims contributor lists. A commit has one author, so the per-author
+// SHA sets add up to the node's distinct commits, however many of its
+// files a commit touched.
+func finalizeTree(n *DirectoryNode) {
+	n.TopContributors = []ContributorShare{}
+	n.Commits = 0
+	for author, shas := range n.authors {
+		n.TopContributors = append(n.TopContributors, ContributorShare{Author: author, Commits: len(shas)})
+		n.Commits += len(shas)
+	}
+	sort.Slice(n.TopContributors, func(i, j int) bool {
+		if n.TopContributors[i].Commits != n.TopContributors[j].Commits {
+			return n.TopContributors[i].Commits > n.TopContributors[j].Commits
+		}
+		return n.TopContributors[i].Author < n.TopContributors[j].Author
+	})
+	if len(n.TopContributors) > gitsense.TreeTopContributors {
+		n.TopContributors = n.TopContributors[:gitsense.TreeTopContributors]
+	}
+
+	for _, child := range n.children {
+		finalizeTree(child)
+		n.Children = append(n.Children, child)
+	}
+	sort.Slice(n.Children, func(i, j int) bool {
+		if n.Children[i].Commits != n.Children[j].Commits {
+			return n.Children[i].Commits > n.Children[j].Commits
+		}
+		return n.Children[i].Name < n.Children[j].Name
+	})
+}
@@ -18,7 +18,7 @@ func GithubLogin(w http.ResponseWriter, r *http.Request) {
 
 	params := url.Values{}
 	params.Set("client_id", githubClientID)
-	params.Set("scope", "repo")
+	params.Set("scope", "repo read:org")
 	if origin != "" {
 		// Reuse GitHub OAuth state to carry extension origin through callback.
 		params.Set("state", origin)
@@ -68,6 +68,15 @@ func ResolveGitHubToken(sessionToken string) (githubToken string, userID int, er
 	return githubToken, userID, nil
 }
 
+// Authenticate resolves the request's session token to a GitHub token and user ID
+func Authenticate(r *http.Request) (githubToken string, userID int, err error) {
+	sessionToken, err := ExtractSessionToken(r)
+	if err != nil {
+		return "", 0, err
+	}
+	return ResolveGitHubToken(sessionToken)
+}
+
 func generateSecureToken() (string, error) {
 	buf := make([]byte, 32)
 	if _, err := rand.Read(buf); err != nil {
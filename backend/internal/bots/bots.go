@@ -0,0 +1,109 @@
+package bots
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"gitsense/internal/settings"
+)
+
+// Well-known automation accounts that don't use the "[bot]" suffix in commit
+// author names, lower-cased
+var knownBots = map[string]bool{
+	"dependabot":           true,
+	"dependabot-preview":   true,
+	"renovate":             true,
+	"renovate bot":         true,
+	"renovate-bot":         true,
+	"renovatebot":          true,
+	"github-actions":       true,
+	"github actions":       true,
+	"greenkeeper":          true,
+	"snyk-bot":             true,
+	"snyk bot":             true,
+	"pre-commit-ci":        true,
+	"imgbot":               true,
+	"allcontributors":      true,
+	"all-contributors":     true,
+	"mergify":              true,
+	"codecov":              true,
+	"semantic-release-bot": true,
+	"pyup-bot":             true,
+	"pyup.io bot":          true,
+	"deepsource-autofix":   true,
+	"copilot":              true,
+}
+
+// IsBot reports whether a commit author looks like an automation account,
+// using the "[bot]" suffix and the built-in list of known bots
+func IsBot(author string) bool {
+	name := strings.ToLower(strings.TrimSpace(author))
+	return strings.HasSuffix(name, "[bot]") || knownBots[name]
+}
+
+// Detector identifies bot authors using the built-in rules plus a repo's
+// configured bot_authors. A nil Detector excludes nobody.
+type Detector struct {
+	extra map[string]bool
+}
+
+// NewDetector creates a detector that also treats the given names as bots
+func NewDetector(extra []string) *Detector {
+	d := &Detector{extra: map[string]bool{}}
+	for _, name := range extra {
+		d.extra[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	return d
+}
+
+// Excludes reports whether commits by author should be left out
+func (d *Detector) Excludes(author string) bool {
+	if d == nil {
+		return false
+	}
+	return IsBot(author) || d.extra[strings.ToLower(strings.TrimSpace(author))]
+}
+
+// ForRepo returns a detector when the repo's settings exclude bots, or nil.
+// Settings that fail to load fall back to the defaults (bots included).
+func ForRepo(repo string) *Detector {
+	b := loadSettings(repo)
+	if b.IncludeBots {
+		return nil
+	}
+	return NewDetector(b.BotAuthors)
+}
+
+// ForRequest resolves bot exclusion for a request: the include_bots query
+// parameter wins, otherwise the repo's setting applies. Returns nil when bots
+// are included; the only error is an invalid include_bots value.
+func ForRequest(r *http.Request, repo string) (*Detector, error) {
+	return ForParam(repo, r.URL.Query().Get("include_bots"))
+}
+
+// ForParam is ForRequest for an include_bots value from elsewhere, such as
+// a command-line flag
+func ForParam(repo, param string) (*Detector, error) {
+	if param == "" {
+		return ForRepo(repo), nil
+	}
+
+	include, err := strconv.ParseBool(param)
+	if err != nil {
+		return nil, fmt.Errorf("invalid include_bots parameter: must be true or false")
+	}
+	if include {
+		return nil, nil
+	}
+	return NewDetector(loadSettings(repo).BotAuthors), nil
+}
+
+func loadSettings(repo string) settings.BotSettings {
+	b, err := settings.GetBotSettings(repo)
+	if err != nil {
+		fmt.Printf("⚠️  %v (repo: %s), using defaults\n", err, repo)
+	}
+	return b
+}
@@ -0,0 +1,132 @@
+package commitkind
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Commit types, following the Conventional Commits specification
+const (
+	Feat     = "feat"
+	Fix      = "fix"
+	Chore    = "chore"
+	Refactor = "refactor"
+	Docs     = "docs"
+	Test     = "test"
+	Perf     = "perf"
+	Style    = "style"
+	Build    = "build"
+	CI       = "ci"
+	Revert   = "revert"
+	Other    = "other"
+)
+
+// Types lists every type Classify can return, in display order
+var Types = []string{Feat, Fix, Refactor, Perf, Docs, Test, Style, Build, CI, Chore, Revert, Other}
+
+// Classification describes a parsed commit message
+type Classification struct {
+	Type         string `json:"type"`
+	Scope        string `json:"scope,omitempty"`
+	Breaking     bool   `json:"breaking"`
+	Conventional bool   `json:"conventional"`
+}
+
+var headerPattern = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?:\s*\S`)
+
+var aliases = map[string]string{
+	"feature":  Feat,
+	"bugfix":   Fix,
+	"hotfix":   Fix,
+	"doc":      Docs,
+	"tests":    Test,
+	"refactor": Refactor,
+	"perf":     Perf,
+	"style":    Style,
+	"build":    Build,
+	"ci":       CI,
+	"chore":    Chore,
+	"revert":   Revert,
+	"feat":     Feat,
+	"fix":      Fix,
+	"docs":     Docs,
+	"test":     Test,
+	"deps":     Build,
+}
+
+// Keyword heuristics for messages that don't follow the convention,
+// checked in order against the lower-cased subject line
+var heuristics = []struct {
+	kind     string
+	keywords []string
+}{
+	{Revert, []string{"revert"}},
+	{Fix, []string{"fix", "bug", "hotfix", "patch", "resolve", "correct", "crash", "broken", "issue"}},
+	{Docs, []string{"readme", "docs", "documentation", "typo", "comment"}},
+	{Test, []string{"test", "spec", "coverage"}},
+	{Refactor, []string{"refactor", "cleanup", "clean up", "restructure", "simplify", "rename", "move", "extract"}},
+	{Perf, []string{"perf", "optimi", "speed up", "faster"}},
+	{Build, []string{"bump", "upgrade", "dependenc", "deps", "dockerfile", "makefile"}},
+	{CI, []string{"workflow", "pipeline", "github action", "ci"}},
+	{Chore, []string{"merge", "release", "version", "chore", "wip", "format", "lint"}},
+	{Feat, []string{"add", "implement", "introduce", "support", "new", "create", "feature", "enable", "allow"}},
+}
+
+var wordPattern = regexp.MustCompile(`[a-z]+`)
+
+// Merge commits are chores whatever their branch names say ("Merge pull
+// request #12 from user/fix-login" is not a fix)
+var mergePattern = regexp.MustCompile(`^merge (pull request|branch|remote-tracking)`)
+
+// Classify parses a commit message as a conventional commit, falling back
+// to keyword heuristics on the subject line for non-conforming messages
+func Classify(message string) Classification {
+	subject := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+	breakingFooter := strings.Contains(message, "BREAKING CHANGE:") || strings.Contains(message, "BREAKING-CHANGE:")
+
+	if m := headerPattern.FindStringSubmatch(subject); m != nil {
+		if kind, ok := aliases[strings.ToLower(m[1])]; ok {
+			return Classification{
+				Type:         kind,
+				Scope:        m[2],
+				Breaking:     m[3] == "!" || breakingFooter,
+				Conventional: true,
+			}
+		}
+	}
+
+	return Classification{
+		Type:     guess(subject),
+		Breaking: breakingFooter,
+	}
+}
+
+func guess(subject string) string {
+	lower := strings.ToLower(subject)
+	words := wordPattern.FindAllString(lower, -1)
+
+	// A revert of a merge starts with "revert", so reverts still win
+	if mergePattern.MatchString(lower) {
+		return Chore
+	}
+
+	for _, h := range heuristics {
+		for _, kw := range h.keywords {
+			if strings.Contains(kw, " ") || len(kw) > 4 {
+				// Longer keywords and phrases match as substrings ("dependenc", "optimi")
+				if strings.Contains(lower, kw) {
+					return h.kind
+				}
+				continue
+			}
+			// Short keywords must start a word ("fixes", "added"), and two-letter
+			// ones must be the whole word, so "ci" doesn't match "decision" or "city"
+			for _, w := range words {
+				if (len(kw) > 2 && strings.HasPrefix(w, kw)) || w == kw {
+					return h.kind
+				}
+			}
+		}
+	}
+	return Other
+}
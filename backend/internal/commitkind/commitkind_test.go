@@ -0,0 +1,38 @@
+package commitkind
+
+import "testing"
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		message      string
+		kind         string
+		scope        string
+		breaking     bool
+		conventional bool
+	}{
+		{"feat(api): add search", Feat, "api", false, true},
+		{"fix!: drop legacy flag", Fix, "", true, true},
+		{"refactor: split db\n\nBREAKING CHANGE: new schema", Refactor, "", true, true},
+		{"hotfix: patch crash", Fix, "", false, true},
+		{"deps: bump modernc", Build, "", false, true},
+		{"unknown: something", Other, "", false, false},
+		{"Fixes crash on startup", Fix, "", false, false},
+		{"Update README", Docs, "", false, false},
+		{"Add retry logic", Feat, "", false, false},
+		{"Bump golang.org/x/net", Build, "", false, false},
+		{"Update decision table", Other, "", false, false},
+		{"Merge pull request #12 from user/fix-login", Chore, "", false, false},
+		{"Merge branch 'bugfix/crash' into main", Chore, "", false, false},
+		{"Merge remote-tracking branch 'origin/hotfix'", Chore, "", false, false},
+		{`Revert "Merge pull request #12 from user/fix-login"`, Revert, "", false, false},
+		{"Revert broken fix", Revert, "", false, false},
+	}
+
+	for _, tt := range tests {
+		got := Classify(tt.message)
+		if got.Type != tt.kind || got.Scope != tt.scope || got.Breaking != tt.breaking || got.Conventional != tt.conventional {
+			t.Errorf("Classify(%q) = %+v, want type %s scope %q breaking %v conventional %v",
+				tt.message, got, tt.kind, tt.scope, tt.breaking, tt.conventional)
+		}
+	}
+}
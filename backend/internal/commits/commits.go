@@ -1,13 +1,21 @@
 package commits
 
 import (
+	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 
 	"gitsense"
+	"gitsense/internal/bots"
+	"gitsense/internal/commitkind"
 	"gitsense/internal/db"
 )
 
+// GetCommits returns a page of a repo's commits, newest first. Follow
+// next_cursor (passed back as cursor) for the next page.
 func GetCommits(w http.ResponseWriter, r *http.Request) {
 	gitsense.SetCORSHeaders(w)
 
@@ -30,15 +38,45 @@ func GetCommits(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	detector, err := bots.ForRequest(r, repo)
+	if err != nil {
+		gitsense.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	cursor, err := gitsense.ValidateCursorParam(r, 2)
+	if err != nil {
+		gitsense.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	total, err := countCommits(repo, detector)
+	if err != nil {
+		gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	// Pages are keyed on (commit_date, sha), newest first. Bot commits are
+	// skipped while scanning, so the limit is applied here rather than in SQL.
 	query := `
 		SELECT commit_sha, author, message, commit_date
 		FROM commits
 		WHERE repo_name = ?
-		ORDER BY commit_date DESC
-		LIMIT ?
+		ORDER BY commit_date DESC, commit_sha DESC
 	`
+	args := []interface{}{repo}
+	if cursor != nil {
+		query = `
+			SELECT commit_sha, author, message, commit_date
+			FROM commits
+			WHERE repo_name = ?
+			  AND (commit_date < ? OR (commit_date = ? AND commit_sha < ?))
+			ORDER BY commit_date DESC, commit_sha DESC
+		`
+		args = append(args, cursor[0], cursor[0], cursor[1])
+	}
 
-	rows, err := db.DB.Query(query, repo, limit)
+	rows, err := db.DB.Query(query, args...)
 
 	if err != nil {
 		gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
@@ -47,13 +85,16 @@ func GetCommits(w http.ResponseWriter, r *http.Request) {
 	defer rows.Close()
 
 	type CommitResponse struct {
-		SHA     string `json:"sha"`
-		Author  string `json:"author"`
-		Message string `json:"message"`
-		Date    string `json:"date"`
+		SHA      string `json:"sha"`
+		Author   string `json:"author"`
+		Message  string `json:"message"`
+		Date     string `json:"date"`
+		Type     string `json:"type"`
+		Breaking bool   `json:"breaking"`
 	}
 
-	var commits []CommitResponse
+	commits := []CommitResponse{}
+	hasMore := false
 
 	for rows.Next() {
 		var c CommitResponse
@@ -61,6 +102,15 @@ func GetCommits(w http.ResponseWriter, r *http.Request) {
 			gitsense.SendJSONError(w, "Failed to scan commit data", http.StatusInternalServerError)
 			return
 		}
+		if detector.Excludes(c.Author) {
+			continue
+		}
+		if len(commits) == limit {
+			hasMore = true
+			break
+		}
+		kind := commitkind.Classify(c.Message)
+		c.Type, c.Breaking = kind.Type, kind.Breaking
 		commits = append(commits, c)
 	}
 
@@ -69,9 +119,243 @@ func GetCommits(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	var nextCursor *string
+	if hasMore {
+		last := commits[len(commits)-1]
+		next := gitsense.EncodeCursor(last.Date, last.SHA)
+		nextCursor = &next
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(commits); err != nil {
+	if err := json.NewEncoder(w).Encode(map[string]interface{}{
+		"commits":     commits,
+		"next_cursor": nextCursor,
+		"total":       total,
+	}); err != nil {
 		gitsense.SendJSONError(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
+
+// countCommits returns how many of a repo's commits the detector keeps
+func countCommits(repo string, detector *bots.Detector) (int, error) {
+	rows, err := db.DB.Query(`
+		SELECT author, COUNT(*) FROM commits
+		WHERE repo_name = ?
+		GROUP BY author
+	`, repo)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	total := 0
+	for rows.Next() {
+		var author string
+		var n int
+		if err := rows.Scan(&author, &n); err != nil {
+			return 0, err
+		}
+		if !detector.Excludes(author) {
+			total += n
+		}
+	}
+	return total, rows.Err()
+}
+
+// ChangedFile is one file touched by a commit. Status is GitHub's (added,
+// modified, removed, renamed, ...), empty for commits synced before it was
+// recorded; PreviousName is set for renames.
+type ChangedFile struct {
+	Name         string `json:"name"`
+	Status       string `json:"status"`
+	PreviousName string `json:"previous_name,omitempty"`
+	Additions    int    `json:"additions"`
+	Deletions    int    `json:"deletions"`
+	Changes      int    `json:"changes"`
+}
+
+// CommitLink points to a parent or child commit. Synced commits carry their
+// date and message and can be opened with the detail endpoint.
+type CommitLink struct {
+	SHA     string `json:"sha"`
+	Synced  bool   `json:"synced"`
+	Date    string `json:"date,omitempty"`
+	Message string `json:"message,omitempty"`
+	URL     string `json:"url,omitempty"`
+	HTMLURL string `json:"html_url,omitempty"`
+}
+
+// GetCommitDetail returns one commit's metadata, the files it changed and
+// links to its parents and synced children. sha may be a unique prefix.
+func GetCommitDetail(w http.ResponseWriter, r *http.Request) {
+	gitsense.SetCORSHeaders(w)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	repo, err := gitsense.ValidateRepoParam(r)
+	if err != nil {
+		gitsense.SendJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	prefix := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("sha")))
+	if len(prefix) < gitsense.MinSHAPrefix || len(prefix) > 40 || strings.Trim(prefix, "0123456789abcdef") != "" {
+		gitsense.SendJSONError(w, fmt.Sprintf("sha must be %d to 40 hex characters", gitsense.MinSHAPrefix), http.StatusBadRequest)
+		return
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT commit_sha, author, author_email, committer, committer_date,
+			message, commit_date, additions, deletions, html_url
+		FROM commits
+		WHERE repo_name = ? AND commit_sha >= ? AND commit_sha < ?
+		LIMIT 2
+	`, repo, prefix, prefix+"g")
+	if err != nil {
+		gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	type CommitDetail struct {
+		Repo          string        `json:"repo"`
+		SHA           string        `json:"sha"`
+		Author        string        `json:"author"`
+		AuthorEmail   string        `json:"author_email"`
+		Committer     string        `json:"committer"`
+		CommitterDate string        `json:"committer_date"`
+		Message       string        `json:"message"`
+		Date          string        `json:"date"`
+		Type          string        `json:"type"`
+		Breaking      bool          `json:"breaking"`
+		Additions     int           `json:"additions"`
+		Deletions     int           `json:"deletions"`
+		HTMLURL       string        `json:"html_url"`
+		Files         []ChangedFile `json:"files"`
+		Parents       []CommitLink  `json:"parents"`
+		Children      []CommitLink  `json:"children"`
+	}
+
+	var matches []CommitDetail
+	for rows.Next() {
+		var c CommitDetail
+		if err := rows.Scan(&c.SHA, &c.Author, &c.AuthorEmail, &c.Committer, &c.CommitterDate,
+			&c.Message, &c.Date, &c.Additions, &c.Deletions, &c.HTMLURL); err != nil {
+			rows.Close()
+			gitsense.SendJSONError(w, "Failed to scan commit data", http.StatusInternalServerError)
+			return
+		}
+		matches = append(matches, c)
+	}
+	rows.Close()
+
+	if len(matches) == 0 {
+		gitsense.SendJSONError(w, "Commit not found", http.StatusNotFound)
+		return
+	}
+	if len(matches) > 1 {
+		gitsense.SendJSONError(w, "sha prefix is ambiguous", http.StatusBadRequest)
+		return
+	}
+
+	c := matches[0]
+	c.Repo = repo
+	kind := commitkind.Classify(c.Message)
+	c.Type, c.Breaking = kind.Type, kind.Breaking
+
+	if c.Files, err = changedFiles(c.SHA); err != nil {
+		gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	// GitHub commit URLs end in the SHA, so related commits share the prefix
+	htmlBase := strings.TrimSuffix(c.HTMLURL, c.SHA)
+	if htmlBase == c.HTMLURL {
+		htmlBase = ""
+	}
+
+	if c.Parents, err = commitLinks(repo, htmlBase, `
+		SELECT p.parent_sha, c.commit_date, c.message
+		FROM commit_parents p
+		LEFT JOIN commits c ON c.commit_sha = p.parent_sha
+		WHERE p.commit_sha = ?
+		ORDER BY p.position
+	`, c.SHA); err != nil {
+		gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	if c.Children, err = commitLinks(repo, htmlBase, `
+		SELECT p.commit_sha, c.commit_date, c.message
+		FROM commit_parents p
+		LEFT JOIN commits c ON c.commit_sha = p.commit_sha
+		WHERE p.parent_sha = ?
+		ORDER BY c.commit_date
+	`, c.SHA); err != nil {
+		gitsense.SendJSONError(w, "Database error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(c); err != nil {
+		gitsense.SendJSONError(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// changedFiles lists the files a commit touched, largest change first
+func changedFiles(sha string) ([]ChangedFile, error) {
+	rows, err := db.DB.Query(`
+		SELECT file_name, status, previous_filename, additions, deletions, changes
+		FROM commit_files
+		WHERE commit_sha = ?
+		ORDER BY changes DESC, file_name ASC
+	`, sha)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := []ChangedFile{}
+	for rows.Next() {
+		var f ChangedFile
+		if err := rows.Scan(&f.Name, &f.Status, &f.PreviousName, &f.Additions, &f.Deletions, &f.Changes); err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, rows.Err()
+}
+
+// commitLinks runs a query returning (sha, date, message) rows, where date
+// and message are NULL for commits that were never synced
+func commitLinks(repo, htmlBase, query, sha string) ([]CommitLink, error) {
+	rows, err := db.DB.Query(query, sha)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	links := []CommitLink{}
+	for rows.Next() {
+		var l CommitLink
+		var date, message sql.NullString
+		if err := rows.Scan(&l.SHA, &date, &message); err != nil {
+			return nil, err
+		}
+		if date.Valid {
+			l.Synced = true
+			l.Date = date.String
+			l.Message = message.String
+			l.URL = "/commits/detail?repo=" + url.QueryEscape(repo) + "&sha=" + l.SHA
+		}
+		if htmlBase != "" {
+			l.HTMLURL = htmlBase + l.SHA
+		}
+		links = append(links, l)
+	}
+	return links, rows.Err()
+}
@@ -107,7 +107,14 @@ func InitDB() error {
 		commit_sha TEXT UNIQUE,
 		author TEXT,
 		message TEXT,
-		commit_date DATETIME
+		commit_date DATETIME,
+		additions INTEGER DEFAULT 0,
+		deletions INTEGER DEFAULT 0,
+		tz_offset_minutes INTEGER,
+		author_email TEXT NOT NULL DEFAULT '',
+		committer TEXT NOT NULL DEFAULT '',
+		committer_date TEXT NOT NULL DEFAULT '',
+		html_url TEXT NOT NULL DEFAULT ''
 	);
 	`
 	if _, err = database.Exec(commitsTable); err != nil {
@@ -126,6 +133,7 @@ func InitDB() error {
 		file_name TEXT,
 		commit_count INTEGER,
 		last_modified DATETIME,
+		size_bytes INTEGER DEFAULT 0,
 		UNIQUE(repo_name, file_name)
 	);
 	`
@@ -133,6 +141,49 @@ func InitDB() error {
 		return fmt.Errorf("failed to create file_activity table: %w", err)
 	}
 
+	// ----------------------------
+	// COMMIT FILES TABLE
+	// Files touched by each commit
+	// ----------------------------
+	commitFilesTable := `
+	CREATE TABLE IF NOT EXISTS commit_files (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_name TEXT,
+		commit_sha TEXT,
+		file_name TEXT,
+		additions INTEGER DEFAULT 0,
+		deletions INTEGER DEFAULT 0,
+		changes INTEGER DEFAULT 0,
+		status TEXT NOT NULL DEFAULT '',
+		previous_filename TEXT NOT NULL DEFAULT '',
+		UNIQUE(commit_sha, file_name)
+	);
+	`
+	if _, err = database.Exec(commitFilesTable); err != nil {
+		return fmt.Errorf("failed to create commit_files table: %w", err)
+	}
+	if _, err = database.Exec(`CREATE INDEX IF NOT EXISTS idx_commit_files_repo ON commit_files(repo_name, file_name)`); err != nil {
+		return fmt.Errorf("failed to create commit_files index: %w", err)
+	}
+
+	// ----------------------------
+	// COMMIT PARENTS TABLE
+	// Parent SHAs of each commit, in GitHub's order (merges have several)
+	// ----------------------------
+	commitParentsTable := `
+	CREATE TABLE IF NOT EXISTS commit_parents (
+		repo_name TEXT NOT NULL,
+		commit_sha TEXT NOT NULL,
+		parent_sha TEXT NOT NULL,
+		position INTEGER NOT NULL,
+		PRIMARY KEY(commit_sha, parent_sha)
+	);
+	CREATE INDEX IF NOT EXISTS idx_commit_parents_parent ON commit_parents(parent_sha);
+	`
+	if _, err = database.Exec(commitParentsTable); err != nil {
+		return fmt.Errorf("failed to create commit_parents table: %w", err)
+	}
+
 	// ----------------------------
 	// REPO SNAPSHOT TABLE
 	// ----------------------------
@@ -151,6 +202,111 @@ func InitDB() error {
 		return fmt.Errorf("failed to create repo_snapshots table: %w", err)
 	}
 
+	// ----------------------------
+	// REPO SETTINGS TABLE
+	// ----------------------------
+	repoSettingsTable := `
+	CREATE TABLE IF NOT EXISTS repo_settings (
+		repo_name TEXT PRIMARY KEY,
+		active_threshold INTEGER NOT NULL,
+		stable_threshold INTEGER NOT NULL,
+		include_bots INTEGER NOT NULL DEFAULT 1,
+		bot_authors TEXT NOT NULL DEFAULT '',
+		score_model TEXT NOT NULL DEFAULT 'ratio',
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	`
+	if _, err = database.Exec(repoSettingsTable); err != nil {
+		return fmt.Errorf("failed to create repo_settings table: %w", err)
+	}
+
+	// ----------------------------
+	// IGNORE RULES TABLE
+	// ----------------------------
+	ignoreRulesTable := `
+	CREATE TABLE IF NOT EXISTS ignore_rules (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_name TEXT NOT NULL,
+		pattern TEXT NOT NULL,
+		source TEXT NOT NULL,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(repo_name, pattern, source)
+	);
+	`
+	if _, err = database.Exec(ignoreRulesTable); err != nil {
+		return fmt.Errorf("failed to create ignore_rules table: %w", err)
+	}
+
+	// ----------------------------
+	// ANOMALY EVENTS TABLE
+	// ----------------------------
+	anomalyEventsTable := `
+	CREATE TABLE IF NOT EXISTS anomaly_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_name TEXT NOT NULL,
+		kind TEXT NOT NULL,
+		subject TEXT NOT NULL DEFAULT '',
+		period_start TEXT NOT NULL,
+		period_end TEXT NOT NULL,
+		observed REAL,
+		expected REAL,
+		message TEXT,
+		detected_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE(repo_name, kind, subject, period_start)
+	);
+	`
+	if _, err = database.Exec(anomalyEventsTable); err != nil {
+		return fmt.Errorf("failed to create anomaly_events table: %w", err)
+	}
+
+	// ----------------------------
+	// CI RUNS TABLE
+	// Completed GitHub Actions runs, for CI health scoring
+	// ----------------------------
+	ciRunsTable := `
+	CREATE TABLE IF NOT EXISTS ci_runs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		repo_name TEXT NOT NULL,
+		run_id INTEGER NOT NULL,
+		workflow_name TEXT,
+		conclusion TEXT,
+		created_at TEXT NOT NULL,
+		UNIQUE(repo_name, run_id)
+	);
+	`
+	if _, err = database.Exec(ciRunsTable); err != nil {
+		return fmt.Errorf("failed to create ci_runs table: %w", err)
+	}
+
+	// ----------------------------
+	// REPO STATS TABLE
+	// Per-repo portfolio aggregates, refreshed on sync
+	// ----------------------------
+	repoStatsTable := `
+	CREATE TABLE IF NOT EXISTS repo_stats (
+		repo_name TEXT PRIMARY KEY,
+		owner TEXT NOT NULL DEFAULT '',
+		total_files INTEGER NOT NULL DEFAULT 0,
+		active_files INTEGER NOT NULL DEFAULT 0,
+		stable_files INTEGER NOT NULL DEFAULT 0,
+		inactive_files INTEGER NOT NULL DEFAULT 0,
+		activity_score REAL NOT NULL DEFAULT 0,
+		project_state TEXT NOT NULL DEFAULT '',
+		score_change REAL NOT NULL DEFAULT 0,
+		trend TEXT NOT NULL DEFAULT 'flat',
+		commits_30d INTEGER NOT NULL DEFAULT 0,
+		velocity REAL NOT NULL DEFAULT 0,
+		previous_velocity REAL NOT NULL DEFAULT 0,
+		top_contributors TEXT NOT NULL DEFAULT '[]',
+		last_synced DATETIME,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP
+	);
+	CREATE INDEX IF NOT EXISTS idx_repo_stats_owner ON repo_stats(owner);
+	`
+	if _, err = database.Exec(repoStatsTable); err != nil {
+		return fmt.Errorf("failed to create repo_stats table: %w", err)
+	}
+
 	// ----------------------------
 	// SESSIONS TABLE
 	// ----------------------------
@@ -170,5 +326,121 @@ func InitDB() error {
 		return fmt.Errorf("failed to create sessions table: %w", err)
 	}
 
+	// ----------------------------
+	// MIGRATIONS
+	// Columns added after the initial schema, for existing databases
+	// ----------------------------
+	migrations := []struct{ table, column, definition string }{
+		{"commits", "additions", "INTEGER DEFAULT 0"},
+		{"commits", "deletions", "INTEGER DEFAULT 0"},
+		{"commit_files", "additions", "INTEGER DEFAULT 0"},
+		{"commit_files", "deletions", "INTEGER DEFAULT 0"},
+		{"commit_files", "changes", "INTEGER DEFAULT 0"},
+		{"file_activity", "size_bytes", "INTEGER DEFAULT 0"},
+		{"commits", "tz_offset_minutes", "INTEGER"},
+		{"repo_settings", "include_bots", "INTEGER NOT NULL DEFAULT 1"},
+		{"repo_settings", "bot_authors", "TEXT NOT NULL DEFAULT ''"},
+		{"repo_settings", "score_model", "TEXT NOT NULL DEFAULT 'ratio'"},
+		{"commits", "author_email", "TEXT NOT NULL DEFAULT ''"},
+		{"commits", "committer", "TEXT NOT NULL DEFAULT ''"},
+		{"commits", "committer_date", "TEXT NOT NULL DEFAULT ''"},
+		{"commits", "html_url", "TEXT NOT NULL DEFAULT ''"},
+		{"commit_files", "status", "TEXT NOT NULL DEFAULT ''"},
+		{"commit_files", "previous_filename", "TEXT NOT NULL DEFAULT ''"},
+	}
+	for _, m := range migrations {
+		if err = addColumnIfMissing(database, m.table, m.column, m.definition); err != nil {
+			return err
+		}
+	}
+
+	// ----------------------------
+	// COMMIT SEARCH INDEX
+	// FTS5 index over commit messages, authors and touched paths, keyed on
+	// commits.id and kept in sync by triggers
+	// ----------------------------
+	searchIndex := `
+	CREATE VIRTUAL TABLE IF NOT EXISTS commits_fts USING fts5(
+		message, author, paths,
+		repo_name UNINDEXED, commit_sha UNINDEXED,
+		tokenize = 'porter unicode61'
+	);
+
+	CREATE TRIGGER IF NOT EXISTS commits_fts_insert AFTER INSERT ON commits BEGIN
+		INSERT INTO commits_fts (rowid, message, author, paths, repo_name, commit_sha)
+		VALUES (new.id, COALESCE(new.message, ''), COALESCE(new.author, ''),
+			COALESCE((SELECT group_concat(file_name, ' ') FROM commit_files WHERE commit_sha = new.commit_sha), ''),
+			new.repo_name, new.commit_sha);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS commits_fts_update AFTER UPDATE OF message, author ON commits BEGIN
+		UPDATE commits_fts SET message = COALESCE(new.message, ''), author = COALESCE(new.author, '')
+		WHERE rowid = new.id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS commits_fts_delete AFTER DELETE ON commits BEGIN
+		DELETE FROM commits_fts WHERE rowid = old.id;
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS commit_files_fts_insert AFTER INSERT ON commit_files BEGIN
+		UPDATE commits_fts SET paths = trim(paths || ' ' || new.file_name)
+		WHERE rowid = (SELECT id FROM commits WHERE commit_sha = new.commit_sha);
+	END;
+
+	CREATE TRIGGER IF NOT EXISTS commit_files_fts_delete AFTER DELETE ON commit_files BEGIN
+		UPDATE commits_fts
+		SET paths = COALESCE((SELECT group_concat(file_name, ' ') FROM commit_files WHERE commit_sha = old.commit_sha), '')
+		WHERE rowid = (SELECT id FROM commits WHERE commit_sha = old.commit_sha);
+	END;
+	`
+	if _, err = database.Exec(searchIndex); err != nil {
+		return fmt.Errorf("failed to create commit search index: %w", err)
+	}
+
+	// Databases created before the index existed are backfilled once
+	var indexed int
+	if err = database.QueryRow(`SELECT COUNT(*) FROM commits_fts`).Scan(&indexed); err != nil {
+		return fmt.Errorf("failed to inspect commit search index: %w", err)
+	}
+	if indexed == 0 {
+		_, err = database.Exec(`
+			INSERT INTO commits_fts (rowid, message, author, paths, repo_name, commit_sha)
+			SELECT c.id, COALESCE(c.message, ''), COALESCE(c.author, ''),
+				COALESCE((SELECT group_concat(cf.file_name, ' ') FROM commit_files cf WHERE cf.commit_sha = c.commit_sha), ''),
+				c.repo_name, c.commit_sha
+			FROM commits c
+		`)
+		if err != nil {
+			return fmt.Errorf("failed to backfill commit search index: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// addColumnIfMissing adds a column to an existing table unless it is already there
+func addColumnIfMissing(database *sql.DB, table, column, definition string) error {
+	rows, err := database.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return fmt.Errorf("failed to inspect %s table: %w", table, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid, notNull, pk int
+		var name, colType string
+		var defaultValue sql.NullString
+		if err := rows.Scan(&cid, &name, &colType, &notNull, &defaultValue, &pk); err != nil {
+			return fmt.Errorf("failed to inspect %s table: %w", table, err)
+		}
+		if name == column {
+			return nil
+		}
+	}
+	rows.Close()
+
+	if _, err := database.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition)); err != nil {
+		return fmt.Errorf("failed to add %s.%s column: %w", table, column, err)
+	}
 	return nil
 }
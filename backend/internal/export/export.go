@@ -0,0 +1,328 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitsense"
+	"gitsense/internal/activity"
+	"gitsense/internal/bots"
+	"gitsense/internal/db"
+	"gitsense/internal/ignore"
+)
+
+// Output formats
+const (
+	FormatCSV    = "csv"
+	FormatNDJSON = "ndjson"
+)
+
+// dataset is an exportable table: its columns, a query returning them for
+// a repo and time range (from, to as RFC3339), which column holds the
+// commit author, if any, for bot exclusion, and which holds a file path, if
+// any, for the repo's ignore rules. Datasets computed rather than read
+// straight from a table have a load function instead of a query.
+type dataset struct {
+	columns     []string
+	query       string
+	load        func(repo string, tr gitsense.TimeRange, detector *bots.Detector) ([][]interface{}, error)
+	authorIndex int
+	fileIndex   int
+}
+
+var datasets = map[string]dataset{
+	"commits": {
+		columns: []string{"repo", "sha", "author", "author_email", "committer", "committer_date",
+			"date", "message", "additions", "deletions", "tz_offset_minutes", "html_url"},
+		query: `
+			SELECT repo_name, commit_sha, author, author_email, committer, committer_date,
+				commit_date, message, additions, deletions, tz_offset_minutes, html_url
+			FROM commits
+			WHERE repo_name = ? AND commit_date >= ? AND commit_date <= ?
+			ORDER BY commit_date, commit_sha
+		`,
+		authorIndex: 2,
+		fileIndex:   -1,
+	},
+	"commit-files": {
+		columns: []string{"repo", "sha", "date", "author", "file", "status", "previous_file",
+			"additions", "deletions", "changes"},
+		query: `
+			SELECT cf.repo_name, cf.commit_sha, c.commit_date, c.author, cf.file_name,
+				cf.status, cf.previous_filename, cf.additions, cf.deletions, cf.changes
+			FROM commit_files cf
+			JOIN commits c ON c.commit_sha = cf.commit_sha
+			WHERE cf.repo_name = ? AND c.commit_date >= ? AND c.commit_date <= ?
+			ORDER BY c.commit_date, cf.commit_sha, cf.file_name
+		`,
+		authorIndex: 3,
+		fileIndex:   4,
+	},
+	"file-activity": {
+		columns:     []string{"repo", "file", "commits", "last_modified", "size_bytes"},
+		load:        loadFileActivity,
+		authorIndex: -1,
+		fileIndex:   -1,
+	},
+	"snapshots": {
+		columns: []string{"repo", "created_at", "active_files", "stable_files", "inactive_files", "activity_score"},
+		query: `
+			SELECT repo_name, created_at, active_files, stable_files, inactive_files, activity_score
+			FROM repo_snapshots
+			WHERE repo_name = ? AND julianday(created_at) BETWEEN julianday(?) AND julianday(?)
+			ORDER BY created_at
+		`,
+		authorIndex: -1,
+		fileIndex:   -1,
+	},
+}
+
+// Datasets lists the exportable dataset names, sorted
+func Datasets() []string {
+	names := make([]string, 0, len(datasets))
+	for name := range datasets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Validate checks a dataset name and format before anything is written
+func Validate(name, format string) error {
+	if _, ok := datasets[name]; !ok {
+		return fmt.Errorf("unknown dataset: %s (available: %s)", name, strings.Join(Datasets(), ", "))
+	}
+	if format != FormatCSV && format != FormatNDJSON {
+		return fmt.Errorf("format must be %s or %s", FormatCSV, FormatNDJSON)
+	}
+	return nil
+}
+
+// ContentType returns the MIME type of a format
+func ContentType(format string) string {
+	if format == FormatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+// Write streams a repo's dataset within the range to out, row by row, and
+// returns the number of rows written. Commits by authors the detector
+// excludes and files matching the repo's ignore rules are skipped. File
+// activity counts only the commits within the range, like /files;
+// snapshots are stored already aggregated and exported as they are.
+func Write(out io.Writer, name, format, repo string, tr gitsense.TimeRange, detector *bots.Detector) (int, error) {
+	if err := Validate(name, format); err != nil {
+		return 0, err
+	}
+	d := datasets[name]
+
+	matcher, err := ignore.ForRepo(repo)
+	if err != nil {
+		return 0, err
+	}
+
+	var w rowWriter
+	if format == FormatNDJSON {
+		w = &ndjsonWriter{enc: json.NewEncoder(out), columns: d.columns}
+	} else {
+		w = &csvWriter{w: csv.NewWriter(out)}
+	}
+
+	n := 0
+	emit := func(values []interface{}) error {
+		if d.authorIndex >= 0 && detector.Excludes(csvValue(values[d.authorIndex])) {
+			return nil
+		}
+		if d.fileIndex >= 0 && matcher.Ignored(csvValue(values[d.fileIndex])) {
+			return nil
+		}
+		if err := w.write(values); err != nil {
+			return err
+		}
+		n++
+		if n%gitsense.ExportFlushRows == 0 {
+			return flush(w, out)
+		}
+		return nil
+	}
+
+	if d.load != nil {
+		records, err := d.load(repo, tr, detector)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load %s: %w", name, err)
+		}
+		if err := writeHeader(w, format, d.columns); err != nil {
+			return 0, err
+		}
+		for _, values := range records {
+			if err := emit(values); err != nil {
+				return n, err
+			}
+		}
+		return n, flush(w, out)
+	}
+
+	from, to := tr.Bounds()
+	rows, err := db.DB.Query(d.query, repo, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query %s: %w", name, err)
+	}
+	defer rows.Close()
+
+	if err := writeHeader(w, format, d.columns); err != nil {
+		return 0, err
+	}
+
+	values := make([]interface{}, len(d.columns))
+	ptrs := make([]interface{}, len(d.columns))
+	for i := range values {
+		ptrs[i] = &values[i]
+	}
+
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return n, err
+		}
+		for i := range values {
+			values[i] = normalize(values[i])
+		}
+		if err := emit(values); err != nil {
+			return n, err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return n, err
+	}
+	return n, flush(w, out)
+}
+
+// writeHeader writes the CSV header row; NDJSON rows carry their own keys
+func writeHeader(w rowWriter, format string, columns []string) error {
+	if format != FormatCSV {
+		return nil
+	}
+	return w.write(toValues(columns))
+}
+
+// loadFileActivity returns the files last changed within the range, with
+// commit counts, dates and bot exclusion as /files computes them
+func loadFileActivity(repo string, tr gitsense.TimeRange, detector *bots.Detector) ([][]interface{}, error) {
+	stats, err := activity.LoadFileStatsInRange(repo, detector, tr)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Name < stats[j].Name })
+
+	var records [][]interface{}
+	for _, f := range stats {
+		if !tr.ContainsTimestamp(f.LastModified) {
+			continue
+		}
+		records = append(records, []interface{}{repo, f.Name, int64(f.CommitCount), f.LastModified, f.SizeBytes})
+	}
+	return records, nil
+}
+
+// flush pushes buffered rows through to out, and on to the client when out
+// is an http.ResponseWriter
+func flush(w rowWriter, out io.Writer) error {
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if f, ok := out.(interface{ Flush() }); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// normalize turns scanned values into the types written out: text as
+// strings and timestamps as RFC3339
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []byte:
+		return string(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339)
+	}
+	return v
+}
+
+type rowWriter interface {
+	write(values []interface{}) error
+	flush() error
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) write(values []interface{}) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		record[i] = csvValue(v)
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type ndjsonWriter struct {
+	enc     *json.Encoder
+	columns []string
+}
+
+func (n *ndjsonWriter) write(values []interface{}) error {
+	// Encoded by hand to keep the column order; json.Encoder sorts map keys
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, v := range values {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		key, _ := json.Marshal(n.columns[i])
+		b.Write(key)
+		b.WriteByte(':')
+		value, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		b.Write(value)
+	}
+	b.WriteByte('}')
+	return n.enc.Encode(json.RawMessage(b.String()))
+}
+
+func (n *ndjsonWriter) flush() error { return nil }
+
+func csvValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func toValues(values []string) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
@@ -0,0 +1,123 @@
+package forecast
+
+import (
+	"fmt"
+	"math"
+)
+
+// Supported models
+const (
+	ModelLinear = "linear"
+	ModelHolt   = "holt"
+)
+
+// z-score for a 95% confidence band
+const z95 = 1.96
+
+// Holt smoothing parameters for level and trend
+const (
+	holtAlpha = 0.5
+	holtBeta  = 0.3
+)
+
+// Point is one forecast step with its confidence band
+type Point struct {
+	Value float64 `json:"value"`
+	Lower float64 `json:"lower"`
+	Upper float64 `json:"upper"`
+}
+
+// Project forecasts horizon steps past the end of an evenly spaced series
+func Project(model string, series []float64, horizon int) ([]Point, error) {
+	if len(series) < 2 {
+		return nil, fmt.Errorf("at least 2 data points are required, got %d", len(series))
+	}
+	switch model {
+	case ModelLinear:
+		return linear(series, horizon), nil
+	case ModelHolt:
+		return holt(series, horizon), nil
+	}
+	return nil, fmt.Errorf("unknown model %q", model)
+}
+
+// linear fits an ordinary least squares trend and returns prediction intervals
+func linear(series []float64, horizon int) []Point {
+	n := float64(len(series))
+	var sumX, sumY float64
+	for i, y := range series {
+		sumX += float64(i)
+		sumY += y
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var sxx, sxy float64
+	for i, y := range series {
+		dx := float64(i) - meanX
+		sxx += dx * dx
+		sxy += dx * (y - meanY)
+	}
+	slope := sxy / sxx
+	intercept := meanY - slope*meanX
+
+	var sse float64
+	for i, y := range series {
+		residual := y - (intercept + slope*float64(i))
+		sse += residual * residual
+	}
+	stdErr := 0.0
+	if n > 2 {
+		stdErr = math.Sqrt(sse / (n - 2))
+	}
+
+	points := make([]Point, horizon)
+	for h := 1; h <= horizon; h++ {
+		x := n - 1 + float64(h)
+		value := intercept + slope*x
+		margin := z95 * stdErr * math.Sqrt(1+1/n+(x-meanX)*(x-meanX)/sxx)
+		points[h-1] = Point{Value: value, Lower: value - margin, Upper: value + margin}
+	}
+	return points
+}
+
+// holt applies double exponential smoothing (level + trend); the band widens
+// with the horizon based on the one-step-ahead residuals
+func holt(series []float64, horizon int) []Point {
+	level := series[0]
+	trend := series[1] - series[0]
+
+	var sse float64
+	for i := 1; i < len(series); i++ {
+		predicted := level + trend
+		residual := series[i] - predicted
+		sse += residual * residual
+
+		prevLevel := level
+		level = holtAlpha*series[i] + (1-holtAlpha)*(level+trend)
+		trend = holtBeta*(level-prevLevel) + (1-holtBeta)*trend
+	}
+	stdErr := math.Sqrt(sse / float64(len(series)-1))
+
+	points := make([]Point, horizon)
+	for h := 1; h <= horizon; h++ {
+		value := level + float64(h)*trend
+		// Variance of the h-step forecast for Holt's linear method
+		variance := 1.0
+		for j := 1; j < h; j++ {
+			c := holtAlpha * (1 + float64(j)*holtBeta)
+			variance += c * c
+		}
+		margin := z95 * stdErr * math.Sqrt(variance)
+		points[h-1] = Point{Value: value, Lower: value - margin, Upper: value + margin}
+	}
+	return points
+}
+
+// Clamp limits a forecast to [min, max], e.g. 0-100 for scores
+func Clamp(points []Point, min, max float64) {
+	for i := range points {
+		points[i].Value = math.Min(math.Max(points[i].Value, min), max)
+		points[i].Lower = math.Min(math.Max(points[i].Lower, min), max)
+		points[i].Upper = math.Min(math.Max(points[i].Upper, min), max)
+	}
+}
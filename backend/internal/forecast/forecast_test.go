@@ -0,0 +1,69 @@
+package forecast
+
+import (
+	"math"
+	"testing"
+)
+
+func TestProject(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   string
+		series  []float64
+		want    []float64 // forecast values
+		exact   bool      // a perfect fit has a zero-width band
+		wantErr bool
+	}{
+		{"linear trend", ModelLinear, []float64{1, 2, 3, 4}, []float64{5, 6, 7}, true, false},
+		{"linear flat", ModelLinear, []float64{3, 3, 3}, []float64{3, 3}, true, false},
+		{"holt trend", ModelHolt, []float64{2, 4, 6, 8}, []float64{10, 12}, true, false},
+		{"too short", ModelLinear, []float64{1}, nil, false, true},
+		{"unknown model", "arima", []float64{1, 2, 3}, nil, false, true},
+	}
+
+	for _, tt := range tests {
+		points, err := Project(tt.model, tt.series, len(tt.want))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		for i, p := range points {
+			if math.Abs(p.Value-tt.want[i]) > 1e-9 {
+				t.Errorf("%s: step %d = %v, want %v", tt.name, i+1, p.Value, tt.want[i])
+			}
+			if p.Lower > p.Value || p.Upper < p.Value {
+				t.Errorf("%s: step %d band [%v, %v] excludes %v", tt.name, i+1, p.Lower, p.Upper, p.Value)
+			}
+			if tt.exact && (math.Abs(p.Upper-p.Lower) > 1e-9) {
+				t.Errorf("%s: step %d band [%v, %v], want zero width", tt.name, i+1, p.Lower, p.Upper)
+			}
+		}
+	}
+}
+
+func TestBandWidensWithHorizon(t *testing.T) {
+	series := []float64{10, 12, 11, 14, 13, 15, 17, 16}
+	for _, model := range []string{ModelLinear, ModelHolt} {
+		points, err := Project(model, series, 5)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 1; i < len(points); i++ {
+			if points[i].Upper-points[i].Lower <= points[i-1].Upper-points[i-1].Lower {
+				t.Errorf("%s: band at step %d is not wider than at step %d", model, i+1, i)
+			}
+		}
+	}
+}
+
+func TestClamp(t *testing.T) {
+	points := []Point{{Value: -5, Lower: -10, Upper: 2}, {Value: 50, Lower: 40, Upper: 60}, {Value: 120, Lower: 90, Upper: 150}}
+	Clamp(points, 0, 100)
+
+	want := []Point{{Value: 0, Lower: 0, Upper: 2}, {Value: 50, Lower: 40, Upper: 60}, {Value: 100, Lower: 90, Upper: 100}}
+	for i := range points {
+		if points[i] != want[i] {
+			t.Errorf("point %d = %+v, want %+v", i, points[i], want[i])
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package github
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"time"
 
 	"gitsense"
 	"gitsense/internal/db"
@@ -12,18 +16,38 @@ import (
 // GitHub API MODELS
 // ----------------------------
 type GitHubCommit struct {
-	SHA    string `json:"sha"`
-	Commit struct {
+	SHA     string `json:"sha"`
+	HTMLURL string `json:"html_url"`
+	Commit  struct {
 		Message string `json:"message"`
 		Author  struct {
+			Name  string `json:"name"`
+			Email string `json:"email"`
+			Date  string `json:"date"`
+		} `json:"author"`
+		Committer struct {
 			Name string `json:"name"`
 			Date string `json:"date"`
-		} `json:"author"`
+		} `json:"committer"`
 	} `json:"commit"`
+	Parents []struct {
+		SHA string `json:"sha"`
+	} `json:"parents"`
 }
 
 type GitHubFile struct {
-	Filename string `json:"filename"`
+	Filename         string `json:"filename"`
+	PreviousFilename string `json:"previous_filename"`
+	Status           string `json:"status"`
+	Additions        int    `json:"additions"`
+	Deletions        int    `json:"deletions"`
+	Changes          int    `json:"changes"`
+}
+
+type GitHubCommitStats struct {
+	Additions int `json:"additions"`
+	Deletions int `json:"deletions"`
+	Total     int `json:"total"`
 }
 
 // ----------------------------
@@ -63,21 +87,44 @@ func SyncFromGitHub(owner, repo, token string) error {
 
 		// 🔹 Save commit into commits table
 		_, err := db.DB.Exec(`
-			INSERT OR IGNORE INTO commits
-			(repo_name, commit_sha, author, message, commit_date)
-			VALUES (?, ?, ?, ?, ?)
+			INSERT INTO commits
+			(repo_name, commit_sha, author, message, commit_date,
+			 author_email, committer, committer_date, html_url)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT(commit_sha)
+			DO UPDATE SET
+				author_email = excluded.author_email,
+				committer = excluded.committer,
+				committer_date = excluded.committer_date,
+				html_url = excluded.html_url
 		`,
 			repo,
 			c.SHA,
 			c.Commit.Author.Name,
 			c.Commit.Message,
 			c.Commit.Author.Date,
+			c.Commit.Author.Email,
+			c.Commit.Committer.Name,
+			c.Commit.Committer.Date,
+			c.HTMLURL,
 		)
 
 		if err != nil {
 			fmt.Printf(" ⚠️  Commit insertion error: %v\n", err)
 		}
 
+		for i, parent := range c.Parents {
+			_, err := db.DB.Exec(`
+				INSERT OR IGNORE INTO commit_parents
+				(repo_name, commit_sha, parent_sha, position)
+				VALUES (?, ?, ?, ?)
+			`, repo, c.SHA, parent.SHA, i)
+
+			if err != nil {
+				fmt.Printf(" ⚠️  Failed to save parent of %s: %v\n", c.SHA[:7], err)
+			}
+		}
+
 		// Fetch files changed in this commit
 		fileURL := fmt.Sprintf(
 			"https://api.github.com/repos/%s/%s/commits/%s",
@@ -98,7 +145,8 @@ func SyncFromGitHub(owner, repo, token string) error {
 		defer resp.Body.Close()
 
 		var detail struct {
-			Files []GitHubFile `json:"files"`
+			Stats GitHubCommitStats `json:"stats"`
+			Files []GitHubFile      `json:"files"`
 		}
 
 		err = json.NewDecoder(resp.Body).Decode(&detail)
@@ -109,6 +157,15 @@ func SyncFromGitHub(owner, repo, token string) error {
 
 		fmt.Printf(" Commit %s: %d files\n", c.SHA[:7], len(detail.Files))
 
+		_, err = db.DB.Exec(`
+			UPDATE commits SET additions = ?, deletions = ?
+			WHERE commit_sha = ?
+		`, detail.Stats.Additions, detail.Stats.Deletions, c.SHA)
+
+		if err != nil {
+			fmt.Printf(" ⚠️  Failed to save stats for %s: %v\n", c.SHA[:7], err)
+		}
+
 		// ----------------------------
 		// UPDATE FILE ACTIVITY
 		// ----------------------------
@@ -135,12 +192,367 @@ func SyncFromGitHub(owner, repo, token string) error {
 			if err != nil {
 				fmt.Printf(" ❌ DB error for %s: %v\n", f.Filename, err)
 			}
+
+			_, err = db.DB.Exec(`
+				INSERT INTO commit_files
+				(repo_name, commit_sha, file_name, additions, deletions, changes, status, previous_filename)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT(commit_sha, file_name)
+				DO UPDATE SET
+					additions = excluded.additions,
+					deletions = excluded.deletions,
+					changes = excluded.changes,
+					status = excluded.status,
+					previous_filename = excluded.previous_filename
+			`,
+				repo,
+				c.SHA,
+				f.Filename,
+				f.Additions,
+				f.Deletions,
+				f.Changes,
+				f.Status,
+				f.PreviousFilename,
+			)
+
+			if err != nil {
+				fmt.Printf(" ❌ DB error recording %s for %s: %v\n", f.Filename, c.SHA[:7], err)
+			}
 		}
 	}
 
+	// ----------------------------
+	// RECORD AUTHOR TIMEZONE OFFSETS
+	// ----------------------------
+	if err := syncCommitOffsets(owner, repo, token, len(commits)); err != nil {
+		fmt.Printf(" ⚠️  Failed to update commit timezones: %v\n", err)
+	}
+
+	// ----------------------------
+	// UPDATE FILE SIZES FROM HEAD TREE
+	// ----------------------------
+	if len(commits) > 0 {
+		if err := syncFileSizes(owner, repo, commits[0].SHA, token); err != nil {
+			fmt.Printf(" ⚠️  Failed to update file sizes: %v\n", err)
+		}
+	}
+
+	// ----------------------------
+	// RECORD RECENT CI RUNS
+	// ----------------------------
+	if err := syncWorkflowRuns(owner, repo, token); err != nil {
+		fmt.Printf(" ⚠️  Failed to record CI runs: %v\n", err)
+	}
+
 	return nil
 }
 
+// ----------------------------
+// COMMIT TIMEZONES
+// The REST API and GraphQL's authoredDate (a DateTime) both normalize
+// commit dates to UTC, so the author's original offset is read from
+// author.date, a GitTimestamp that keeps it.
+// ----------------------------
+func syncCommitOffsets(owner, repo, token string, count int) error {
+	if count == 0 {
+		return nil
+	}
+
+	query := map[string]interface{}{
+		"query": `query($owner: String!, $name: String!, $count: Int!) {
+			repository(owner: $owner, name: $name) {
+				defaultBranchRef {
+					target {
+						... on Commit {
+							history(first: $count) {
+								nodes { oid author { date } }
+							}
+						}
+					}
+				}
+			}
+		}`,
+		"variables": map[string]interface{}{
+			"owner": owner,
+			"name":  repo,
+			"count": count,
+		},
+	}
+	body, err := json.Marshal(query)
+	if err != nil {
+		return err
+	}
+
+	req, err := gitsense.CreateGitHubRequest("POST", "https://api.github.com/graphql", token)
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	client := gitsense.CreateHTTPClient(gitsense.GitHubAPITimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from GraphQL", resp.StatusCode)
+	}
+
+	var result struct {
+		Data struct {
+			Repository struct {
+				DefaultBranchRef struct {
+					Target struct {
+						History struct {
+							Nodes []struct {
+								OID    string `json:"oid"`
+								Author struct {
+									Date string `json:"date"`
+								} `json:"author"`
+							} `json:"nodes"`
+						} `json:"history"`
+					} `json:"target"`
+				} `json:"defaultBranchRef"`
+			} `json:"repository"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+
+	for _, node := range result.Data.Repository.DefaultBranchRef.Target.History.Nodes {
+		t, err := time.Parse(time.RFC3339, node.Author.Date)
+		if err != nil {
+			continue
+		}
+		_, offset := t.Zone()
+		if _, err := db.DB.Exec(`
+			UPDATE commits SET tz_offset_minutes = ?
+			WHERE commit_sha = ?
+		`, offset/60, node.OID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ----------------------------
+// FILE SIZES
+// Blob sizes come from the recursive git tree of the given commit.
+// Tracked files missing from the tree were deleted and get size 0.
+// ----------------------------
+func syncFileSizes(owner, repo, ref, token string) error {
+	url := fmt.Sprintf(
+		"https://api.github.com/repos/%s/%s/git/trees/%s?recursive=1",
+		owner, repo, ref,
+	)
+
+	req, err := gitsense.CreateGitHubRequest("GET", url, token)
+	if err != nil {
+		return err
+	}
+
+	client := gitsense.CreateHTTPClient(gitsense.GitHubAPITimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d fetching tree", resp.StatusCode)
+	}
+
+	var tree struct {
+		Truncated bool `json:"truncated"`
+		Tree      []struct {
+			Path string `json:"path"`
+			Type string `json:"type"`
+			Size int64  `json:"size"`
+		} `json:"tree"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tree); err != nil {
+		return err
+	}
+
+	sizes := map[string]int64{}
+	for _, entry := range tree.Tree {
+		if entry.Type == "blob" {
+			sizes[entry.Path] = entry.Size
+		}
+	}
+
+	rows, err := db.DB.Query(`SELECT file_name FROM file_activity WHERE repo_name = ?`, repo)
+	if err != nil {
+		return err
+	}
+	var tracked []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err == nil {
+			tracked = append(tracked, name)
+		}
+	}
+	rows.Close()
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, name := range tracked {
+		size, ok := sizes[name]
+		if !ok && tree.Truncated {
+			continue // unknown rather than deleted
+		}
+		if _, err := tx.Exec(`
+			UPDATE file_activity SET size_bytes = ?
+			WHERE repo_name = ? AND file_name = ?
+		`, size, repo, name); err != nil {
+			return err
+		}
+	}
+
+	if tree.Truncated {
+		fmt.Printf(" ⚠️  Tree for %s was truncated, some file sizes were not updated\n", repo)
+	}
+	return tx.Commit()
+}
+
+// ----------------------------
+// CI RUNS
+// Stores the latest completed GitHub Actions runs for CI health scoring.
+// Repos without Actions simply record nothing.
+// ----------------------------
+func syncWorkflowRuns(owner, repo, token string) error {
+	url := fmt.Sprintf(
+		"https://api.github.com/repos/%s/%s/actions/runs?status=completed&per_page=%d",
+		owner, repo, gitsense.MaxCIRunsPerSync,
+	)
+
+	req, err := gitsense.CreateGitHubRequest("GET", url, token)
+	if err != nil {
+		return err
+	}
+
+	client := gitsense.CreateHTTPClient(gitsense.GitHubAPITimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d fetching workflow runs", resp.StatusCode)
+	}
+
+	var data struct {
+		WorkflowRuns []struct {
+			ID         int64  `json:"id"`
+			Name       string `json:"name"`
+			Conclusion string `json:"conclusion"`
+			CreatedAt  string `json:"created_at"`
+		} `json:"workflow_runs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return err
+	}
+
+	for _, run := range data.WorkflowRuns {
+		_, err := db.DB.Exec(`
+			INSERT INTO ci_runs (repo_name, run_id, workflow_name, conclusion, created_at)
+			VALUES (?, ?, ?, ?, ?)
+			ON CONFLICT(repo_name, run_id) DO UPDATE SET conclusion = excluded.conclusion
+		`, repo, run.ID, run.Name, run.Conclusion, run.CreatedAt)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ----------------------------
+// FETCH REPO FILE
+// Returns nil content (and no error) when the file does not exist
+// ----------------------------
+func FetchRepoFile(owner, repo, filePath, token string) ([]byte, error) {
+	url := fmt.Sprintf(
+		"https://api.github.com/repos/%s/%s/contents/%s",
+		owner, repo, filePath,
+	)
+
+	req, err := gitsense.CreateGitHubRequest("GET", url, token)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github.raw")
+
+	client := gitsense.CreateHTTPClient(gitsense.DefaultTimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, filePath)
+	}
+
+	// Read one byte past the limit to tell a full file from a truncated one
+	data, err := io.ReadAll(io.LimitReader(resp.Body, gitsense.MaxRepoConfigBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > gitsense.MaxRepoConfigBytes {
+		return nil, fmt.Errorf("%s is larger than %d bytes", filePath, gitsense.MaxRepoConfigBytes)
+	}
+	return data, nil
+}
+
+// IsOrgMember reports whether the token's user is an active member of the org
+func IsOrgMember(org, token string) (bool, error) {
+	url := fmt.Sprintf("https://api.github.com/user/memberships/orgs/%s", org)
+
+	req, err := gitsense.CreateGitHubRequest("GET", url, token)
+	if err != nil {
+		return false, err
+	}
+
+	client := gitsense.CreateHTTPClient(gitsense.DefaultTimeout)
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	// GitHub answers 404 (or 403 without the read:org scope) for non-members
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("unexpected status %d checking %s membership", resp.StatusCode, org)
+	}
+
+	var membership struct {
+		State string `json:"state"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&membership); err != nil {
+		return false, err
+	}
+	return membership.State == "active", nil
+}
+
 // ----------------------------
 // FETCH GITHUB USERNAME
 // ----------------------------
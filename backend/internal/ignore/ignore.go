@@ -0,0 +1,343 @@
+package ignore
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"gitsense"
+	"gitsense/internal/db"
+)
+
+// Rule sources. Config rules are replaced on every sync from the repo's
+// .gitsense.yml, user rules are managed through the API.
+const (
+	SourceUser   = "user"
+	SourceConfig = "config"
+)
+
+// ConfigFileName is the optional file committed in a repo to declare ignore rules
+const ConfigFileName = ".gitsense.yml"
+
+type rule struct {
+	pattern  string
+	segments []string
+	negate   bool
+	dirOnly  bool
+	anchored bool
+}
+
+// Matcher applies gitignore-style patterns to repo-relative paths.
+// As in .gitignore, the last matching pattern wins and "!" re-includes a path.
+type Matcher struct {
+	rules []rule
+}
+
+// NewMatcher compiles the given patterns, skipping blank lines and comments
+func NewMatcher(patterns []string) (*Matcher, error) {
+	m := &Matcher{}
+	for _, p := range patterns {
+		r, ok, err := compile(p)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			m.rules = append(m.rules, r)
+		}
+	}
+	return m, nil
+}
+
+func compile(raw string) (rule, bool, error) {
+	p := strings.TrimSpace(raw)
+	if p == "" || strings.HasPrefix(p, "#") {
+		return rule{}, false, nil
+	}
+
+	r := rule{pattern: p}
+	if strings.HasPrefix(p, "!") {
+		r.negate = true
+		p = p[1:]
+	}
+	if strings.HasSuffix(p, "/") {
+		r.dirOnly = true
+		p = strings.TrimRight(p, "/")
+	}
+	if strings.Contains(p, "/") {
+		r.anchored = true
+		p = strings.TrimLeft(p, "/")
+	}
+	if p == "" {
+		return rule{}, false, fmt.Errorf("invalid ignore pattern %q", raw)
+	}
+
+	r.segments = strings.Split(p, "/")
+	for _, seg := range r.segments {
+		if _, err := path.Match(seg, ""); err != nil {
+			return rule{}, false, fmt.Errorf("invalid ignore pattern %q: %w", raw, err)
+		}
+	}
+	return r, true, nil
+}
+
+// Ignored reports whether the path is excluded by the matcher's rules
+func (m *Matcher) Ignored(filePath string) bool {
+	if m == nil || len(m.rules) == 0 {
+		return false
+	}
+
+	segs := strings.Split(strings.TrimLeft(filePath, "/"), "/")
+	ignored := false
+	for _, r := range m.rules {
+		if r.matches(segs) {
+			ignored = !r.negate
+		}
+	}
+	return ignored
+}
+
+// Empty reports whether the matcher has no rules
+func (m *Matcher) Empty() bool {
+	return m == nil || len(m.rules) == 0
+}
+
+// matches checks the path itself and every parent directory, so that a
+// pattern matching a directory excludes everything beneath it.
+func (r rule) matches(segs []string) bool {
+	last := len(segs) - 1
+
+	if !r.anchored {
+		for i, seg := range segs {
+			if r.dirOnly && i == last {
+				break
+			}
+			if ok, _ := path.Match(r.segments[0], seg); ok {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i := range segs {
+		if r.dirOnly && i == last {
+			break
+		}
+		if matchSegments(r.segments, segs[:i+1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, segs []string) bool {
+	if len(pattern) == 0 {
+		return len(segs) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segs); i++ {
+			if matchSegments(pattern[1:], segs[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segs) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], segs[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], segs[1:])
+}
+
+// ParseConfig extracts the ignore list from a .gitsense.yml file.
+// Only the top-level "ignore:" sequence is read, e.g.
+//
+//	ignore:
+//	  - vendor/
+//	  - "*.pb.go" # generated
+//
+// Flow-style lists (ignore: [a, b]) are rejected rather than skipped, and
+// so are configs with more than MaxIgnorePatterns patterns.
+func ParseConfig(data []byte) ([]string, error) {
+	var patterns []string
+	inIgnore := false
+
+	for n, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		indented := line[0] == ' ' || line[0] == '\t'
+		if !indented {
+			key := strings.TrimSpace(strings.SplitN(trimmed, "#", 2)[0])
+			inIgnore = key == "ignore:"
+			if !inIgnore && strings.HasPrefix(key, "ignore:") {
+				return nil, fmt.Errorf("%s line %d: ignore must be a block list, one \"- pattern\" per line", ConfigFileName, n+1)
+			}
+			continue
+		}
+		if !inIgnore {
+			continue
+		}
+		if !strings.HasPrefix(trimmed, "- ") && trimmed != "-" {
+			return nil, fmt.Errorf("%s line %d: expected list item under ignore", ConfigFileName, n+1)
+		}
+
+		item, err := configItem(strings.TrimSpace(strings.TrimPrefix(trimmed, "-")))
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %v", ConfigFileName, n+1, err)
+		}
+		if item != "" {
+			patterns = append(patterns, item)
+		}
+	}
+
+	if len(patterns) > gitsense.MaxIgnorePatterns {
+		return nil, fmt.Errorf("%s has %d patterns, at most %d are allowed", ConfigFileName, len(patterns), gitsense.MaxIgnorePatterns)
+	}
+
+	if _, err := NewMatcher(patterns); err != nil {
+		return nil, err
+	}
+	return patterns, nil
+}
+
+// configItem reads one list item: a plain value whose trailing " # comment"
+// is dropped, or a quoted one, which may contain # and be followed by a comment
+func configItem(item string) (string, error) {
+	if item == "" || (item[0] != '"' && item[0] != '\'') {
+		if i := strings.Index(item, " #"); i >= 0 {
+			item = strings.TrimSpace(item[:i])
+		}
+		return item, nil
+	}
+
+	end := strings.IndexByte(item[1:], item[0])
+	if end < 0 {
+		return "", fmt.Errorf("unterminated quote")
+	}
+	if rest := strings.TrimSpace(item[end+2:]); rest != "" && !strings.HasPrefix(rest, "#") {
+		return "", fmt.Errorf("unexpected %q after quoted pattern", rest)
+	}
+	return item[1 : end+1], nil
+}
+
+// ----------------------------
+// STORAGE
+// ----------------------------
+
+// Rule is an ignore pattern stored for a repo
+type Rule struct {
+	Pattern string `json:"pattern"`
+	Source  string `json:"source"`
+}
+
+// GetRules returns every ignore rule stored for a repo, config rules first
+func GetRules(repo string) ([]Rule, error) {
+	rows, err := db.DB.Query(`
+		SELECT pattern, source
+		FROM ignore_rules
+		WHERE repo_name = ?
+		ORDER BY CASE source WHEN ? THEN 0 ELSE 1 END, id ASC
+	`, repo, SourceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load ignore rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []Rule{}
+	for rows.Next() {
+		var r Rule
+		if err := rows.Scan(&r.Pattern, &r.Source); err != nil {
+			return nil, fmt.Errorf("failed to scan ignore rule: %w", err)
+		}
+		rules = append(rules, r)
+	}
+	return rules, rows.Err()
+}
+
+// ReplaceRules overwrites all rules of one source for a repo and reports
+// whether the stored set of patterns changed
+func ReplaceRules(repo, source string, patterns []string) (bool, error) {
+	if _, err := NewMatcher(patterns); err != nil {
+		return false, err
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`SELECT pattern FROM ignore_rules WHERE repo_name = ? AND source = ?`, repo, source)
+	if err != nil {
+		return false, fmt.Errorf("failed to load ignore rules: %w", err)
+	}
+	previous := map[string]bool{}
+	for rows.Next() {
+		var p string
+		if err := rows.Scan(&p); err != nil {
+			rows.Close()
+			return false, fmt.Errorf("failed to scan ignore rule: %w", err)
+		}
+		previous[p] = true
+	}
+	rows.Close()
+
+	if _, err := tx.Exec(`DELETE FROM ignore_rules WHERE repo_name = ? AND source = ?`, repo, source); err != nil {
+		return false, fmt.Errorf("failed to clear ignore rules: %w", err)
+	}
+	current := map[string]bool{}
+	for _, p := range patterns {
+		p = strings.TrimSpace(p)
+		if p == "" || strings.HasPrefix(p, "#") {
+			continue
+		}
+		current[p] = true
+		if _, err := tx.Exec(`
+			INSERT OR IGNORE INTO ignore_rules (repo_name, pattern, source)
+			VALUES (?, ?, ?)
+		`, repo, p, source); err != nil {
+			return false, fmt.Errorf("failed to save ignore rule: %w", err)
+		}
+	}
+
+	changed := len(current) != len(previous)
+	for p := range current {
+		if !previous[p] {
+			changed = true
+		}
+	}
+	return changed, tx.Commit()
+}
+
+// ForRepo returns a matcher built from all stored rules of a repo
+func ForRepo(repo string) (*Matcher, error) {
+	rules, err := GetRules(repo)
+	if err != nil {
+		return nil, err
+	}
+	patterns := make([]string, 0, len(rules))
+	for _, r := range rules {
+		patterns = append(patterns, r.Pattern)
+	}
+	return NewMatcher(patterns)
+}
+
+// MatcherCache memoizes matchers for handlers that read files across many repos
+type MatcherCache map[string]*Matcher
+
+// Get returns the cached matcher for a repo, loading it on first use
+func (c MatcherCache) Get(repo string) *Matcher {
+	if m, ok := c[repo]; ok {
+		return m
+	}
+	m, err := ForRepo(repo)
+	if err != nil {
+		fmt.Printf("⚠️  %v (repo: %s), ignoring no files\n", err, repo)
+	}
+	c[repo] = m
+	return m
+}
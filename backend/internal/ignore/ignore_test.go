@@ -0,0 +1,67 @@
+package ignore
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    []string
+		wantErr bool
+	}{
+		{"block list", "ignore:\n  - vendor/\n  - \"*.pb.go\"\n", []string{"vendor/", "*.pb.go"}, false},
+		{"quoted with comment", "ignore:\n  - \"*.pb.go\" # generated\n  - '*.lock'   # deps\n", []string{"*.pb.go", "*.lock"}, false},
+		{"plain with comment", "ignore:\n  - dist/ # build output\n", []string{"dist/"}, false},
+		{"hash inside quotes", "ignore:\n  - \"docs/#drafts\"\n", []string{"docs/#drafts"}, false},
+		{"other keys skipped", "name: x\nignore: # rules\n  - a\nother:\n  - b\n", []string{"a"}, false},
+		{"empty", "", nil, false},
+		{"flow list", "ignore: [vendor/, dist/]\n", nil, true},
+		{"not a list item", "ignore:\n  vendor/\n", nil, true},
+		{"unterminated quote", "ignore:\n  - \"vendor/\n", nil, true},
+		{"text after quote", "ignore:\n  - \"a\" b\n", nil, true},
+		{"invalid pattern", "ignore:\n  - \"[\"\n", nil, true},
+		{"too many patterns", "ignore:\n" + strings.Repeat("  - a\n", 201), nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseConfig([]byte(tt.config))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: got %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatcherIgnored(t *testing.T) {
+	m, err := NewMatcher([]string{"vendor/", "*.lock", "/build", "**/*.pb.go", "!keep.lock", "# comment"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{"vendor/x/y.go", true},
+		{"sub/vendor/x.go", true},
+		{"go.lock", true},
+		{"deps/yarn.lock", true},
+		{"keep.lock", false},
+		{"build/out.bin", true},
+		{"src/build/out.bin", false},
+		{"api/v1/types.pb.go", true},
+		{"main.go", false},
+	}
+	for _, tt := range tests {
+		if got := m.Ignored(tt.path); got != tt.want {
+			t.Errorf("Ignored(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
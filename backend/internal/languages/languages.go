@@ -0,0 +1,152 @@
+package languages
+
+import (
+	"path"
+	"strings"
+)
+
+// Other is reported for paths that match no known language or file type
+const Other = "Other"
+
+// Well-known file names, matched case-insensitively on the base name
+var fileNames = map[string]string{
+	"dockerfile":          "Dockerfile",
+	"makefile":            "Makefile",
+	"gnumakefile":         "Makefile",
+	"cmakelists.txt":      "CMake",
+	"jenkinsfile":         "Groovy",
+	"rakefile":            "Ruby",
+	"gemfile":             "Ruby",
+	"gemfile.lock":        "Lockfile",
+	"go.mod":              "Go Module",
+	"go.sum":              "Lockfile",
+	"package.json":        "JSON",
+	"package-lock.json":   "Lockfile",
+	"yarn.lock":           "Lockfile",
+	"pnpm-lock.yaml":      "Lockfile",
+	"cargo.lock":          "Lockfile",
+	"poetry.lock":         "Lockfile",
+	"composer.lock":       "Lockfile",
+	"license":             "Text",
+	"readme":              "Markdown",
+	".gitignore":          "Config",
+	".gitattributes":      "Config",
+	".dockerignore":       "Config",
+	".editorconfig":       "Config",
+	".env.example":        "Config",
+	"requirements.txt":    "Python Requirements",
+	"build.gradle":        "Gradle",
+	"settings.gradle":     "Gradle",
+	"build.gradle.kts":    "Gradle",
+	"settings.gradle.kts": "Gradle",
+}
+
+// File extensions, matched case-insensitively including the leading dot
+var extensions = map[string]string{
+	".go":      "Go",
+	".ts":      "TypeScript",
+	".tsx":     "TypeScript",
+	".mts":     "TypeScript",
+	".cts":     "TypeScript",
+	".js":      "JavaScript",
+	".jsx":     "JavaScript",
+	".mjs":     "JavaScript",
+	".cjs":     "JavaScript",
+	".py":      "Python",
+	".pyi":     "Python",
+	".ipynb":   "Jupyter Notebook",
+	".rb":      "Ruby",
+	".java":    "Java",
+	".kt":      "Kotlin",
+	".kts":     "Kotlin",
+	".scala":   "Scala",
+	".groovy":  "Groovy",
+	".rs":      "Rust",
+	".c":       "C",
+	".h":       "C",
+	".cc":      "C++",
+	".cpp":     "C++",
+	".cxx":     "C++",
+	".hpp":     "C++",
+	".hh":      "C++",
+	".cs":      "C#",
+	".fs":      "F#",
+	".swift":   "Swift",
+	".m":       "Objective-C",
+	".mm":      "Objective-C",
+	".php":     "PHP",
+	".pl":      "Perl",
+	".lua":     "Lua",
+	".r":       "R",
+	".dart":    "Dart",
+	".ex":      "Elixir",
+	".exs":     "Elixir",
+	".erl":     "Erlang",
+	".hs":      "Haskell",
+	".clj":     "Clojure",
+	".zig":     "Zig",
+	".sh":      "Shell",
+	".bash":    "Shell",
+	".zsh":     "Shell",
+	".ps1":     "PowerShell",
+	".sql":     "SQL",
+	".proto":   "Protocol Buffers",
+	".graphql": "GraphQL",
+	".gql":     "GraphQL",
+	".html":    "HTML",
+	".htm":     "HTML",
+	".css":     "CSS",
+	".scss":    "SCSS",
+	".sass":    "SCSS",
+	".less":    "Less",
+	".vue":     "Vue",
+	".svelte":  "Svelte",
+	".md":      "Markdown",
+	".mdx":     "Markdown",
+	".rst":     "reStructuredText",
+	".txt":     "Text",
+	".json":    "JSON",
+	".yaml":    "YAML",
+	".yml":     "YAML",
+	".toml":    "TOML",
+	".xml":     "XML",
+	".ini":     "Config",
+	".cfg":     "Config",
+	".conf":    "Config",
+	".env":     "Config",
+	".tf":      "Terraform",
+	".hcl":     "HCL",
+	".lock":    "Lockfile",
+	".png":     "Image",
+	".jpg":     "Image",
+	".jpeg":    "Image",
+	".gif":     "Image",
+	".svg":     "Image",
+	".ico":     "Image",
+	".webp":    "Image",
+	".csv":     "Data",
+	".tsv":     "Data",
+}
+
+// Detect returns the language or file type of a repo-relative path
+func Detect(filePath string) string {
+	base := strings.ToLower(path.Base(filePath))
+
+	if lang, ok := fileNames[base]; ok {
+		return lang
+	}
+
+	// Generated protobuf code is tracked separately from hand-written sources
+	if strings.HasSuffix(base, ".pb.go") || strings.HasSuffix(base, "_pb2.py") {
+		return "Generated"
+	}
+
+	if strings.HasPrefix(base, "dockerfile.") || strings.HasSuffix(base, ".dockerfile") {
+		return "Dockerfile"
+	}
+
+	if lang, ok := extensions[path.Ext(base)]; ok {
+		return lang
+	}
+	return Other
+}
@@ -1,10 +1,19 @@
 package models
 
+import (
+	"gitsense"
+	"gitsense/internal/scoring"
+)
+
 type ProjectSummary struct {
-	TotalFiles    int     `json:"total_files"`
-	ActiveFiles   int     `json:"active_files"`
-	StableFiles   int     `json:"stable_files"`
-	InactiveFiles int     `json:"inactive_files"`
-	ActivityScore float64 `json:"activity_score"`
-	ProjectState  string  `json:"project_state"`
+	Repo            string              `json:"repo,omitempty"`
+	Range           *gitsense.TimeRange `json:"range,omitempty"`
+	TotalFiles      int                 `json:"total_files"`
+	ActiveFiles     int                 `json:"active_files"`
+	StableFiles     int                 `json:"stable_files"`
+	InactiveFiles   int                 `json:"inactive_files"`
+	ActivityScore   float64             `json:"activity_score"`
+	ProjectState    string              `json:"project_state"`
+	ScoreModel      string              `json:"score_model,omitempty"`
+	ScoreComponents []scoring.Component `json:"score_components,omitempty"`
 }
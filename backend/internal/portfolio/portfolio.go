@@ -0,0 +1,337 @@
+package portfolio
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitsense"
+	"gitsense/internal/activity"
+	"gitsense/internal/bots"
+	"gitsense/internal/db"
+)
+
+// Trend directions, based on the activity score change over PortfolioTrendDays
+const (
+	TrendUp   = "up"
+	TrendDown = "down"
+	TrendFlat = "flat"
+)
+
+type ContributorCount struct {
+	Author  string `json:"author"`
+	Commits int    `json:"commits"`
+}
+
+// Entry is one repo's precomputed portfolio row
+type Entry struct {
+	Repo             string             `json:"repo"`
+	Owner            string             `json:"owner,omitempty"`
+	TotalFiles       int                `json:"total_files"`
+	ActiveFiles      int                `json:"active_files"`
+	StableFiles      int                `json:"stable_files"`
+	InactiveFiles    int                `json:"inactive_files"`
+	ActivityScore    float64            `json:"activity_score"`
+	ProjectState     string             `json:"project_state"`
+	ScoreChange      float64            `json:"score_change"`
+	Trend            string             `json:"trend"`
+	Commits30d       int                `json:"commits_30d"`
+	Velocity         float64            `json:"velocity"`
+	PreviousVelocity float64            `json:"previous_velocity"`
+	TopContributors  []ContributorCount `json:"top_contributors"`
+	LastSynced       string             `json:"last_synced,omitempty"`
+	UpdatedAt        string             `json:"updated_at"`
+	Stale            bool               `json:"stale"`
+}
+
+// Refresh recomputes a repo's portfolio row. An empty owner keeps the one
+// already stored, so callers that don't know it (e.g. settings changes)
+// don't clear it.
+func Refresh(repo, owner string) error {
+	detector := bots.ForRepo(repo)
+
+	summary, err := activity.Summarize(repo, detector, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to summarize %s: %w", repo, err)
+	}
+
+	scoreChange, err := scoreChangeSince(repo, summary.ActivityScore, gitsense.PortfolioTrendDays)
+	if err != nil {
+		return err
+	}
+	trend := TrendFlat
+	if scoreChange >= gitsense.PortfolioTrendMinChange {
+		trend = TrendUp
+	} else if scoreChange <= -gitsense.PortfolioTrendMinChange {
+		trend = TrendDown
+	}
+
+	commits30d, velocity, previousVelocity, top, err := commitActivity(repo, detector)
+	if err != nil {
+		return err
+	}
+	topJSON, err := json.Marshal(top)
+	if err != nil {
+		return err
+	}
+
+	var lastSynced sql.NullString
+	db.DB.QueryRow(`
+		SELECT MAX(last_synced) FROM user_repos WHERE repo_name = ?
+	`, repo).Scan(&lastSynced)
+
+	_, err = db.DB.Exec(`
+		INSERT INTO repo_stats
+		(repo_name, owner, total_files, active_files, stable_files, inactive_files,
+		 activity_score, project_state, score_change, trend,
+		 commits_30d, velocity, previous_velocity, top_contributors, last_synced, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(repo_name) DO UPDATE SET
+			owner = CASE WHEN excluded.owner = '' THEN repo_stats.owner ELSE excluded.owner END,
+			total_files = excluded.total_files,
+			active_files = excluded.active_files,
+			stable_files = excluded.stable_files,
+			inactive_files = excluded.inactive_files,
+			activity_score = excluded.activity_score,
+			project_state = excluded.project_state,
+			score_change = excluded.score_change,
+			trend = excluded.trend,
+			commits_30d = excluded.commits_30d,
+			velocity = excluded.velocity,
+			previous_velocity = excluded.previous_velocity,
+			top_contributors = excluded.top_contributors,
+			last_synced = excluded.last_synced,
+			updated_at = CURRENT_TIMESTAMP
+	`, repo, owner, summary.TotalFiles, summary.ActiveFiles, summary.StableFiles, summary.InactiveFiles,
+		summary.ActivityScore, summary.ProjectState, scoreChange, trend,
+		commits30d, velocity, previousVelocity, string(topJSON), lastSynced)
+	if err != nil {
+		return fmt.Errorf("failed to save repo stats: %w", err)
+	}
+	return nil
+}
+
+// scoreChangeSince compares the current score with the latest snapshot at
+// least days old, falling back to the oldest snapshot for young repos
+func scoreChangeSince(repo string, current float64, days int) (float64, error) {
+	cutoff := time.Now().UTC().AddDate(0, 0, -days).Format("2006-01-02 15:04:05")
+
+	var previous sql.NullFloat64
+	err := db.DB.QueryRow(`
+		SELECT activity_score FROM repo_snapshots
+		WHERE repo_name = ? AND created_at <= ?
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, repo, cutoff).Scan(&previous)
+	if err == sql.ErrNoRows {
+		err = db.DB.QueryRow(`
+			SELECT activity_score FROM repo_snapshots
+			WHERE repo_name = ?
+			ORDER BY created_at ASC
+			LIMIT 1
+		`, repo).Scan(&previous)
+	}
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to load snapshots: %w", err)
+	}
+	return current - previous.Float64, nil
+}
+
+// commitActivity returns the commit count of the last 30 days, weekly
+// velocity over the current and previous PortfolioVelocityDays windows, and
+// the most active recent contributors
+func commitActivity(repo string, detector *bots.Detector) (int, float64, float64, []ContributorCount, error) {
+	rows, err := db.DB.Query(`
+		SELECT author, commit_date FROM commits WHERE repo_name = ?
+	`, repo)
+	if err != nil {
+		return 0, 0, 0, nil, fmt.Errorf("failed to load commits: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now().UTC()
+	last30 := now.AddDate(0, 0, -30)
+	window := now.AddDate(0, 0, -gitsense.PortfolioVelocityDays)
+	previousWindow := now.AddDate(0, 0, -2*gitsense.PortfolioVelocityDays)
+	contributorWindow := now.AddDate(0, 0, -gitsense.PortfolioContributorDays)
+
+	commits30d, current, previous := 0, 0, 0
+	byAuthor := map[string]int{}
+
+	for rows.Next() {
+		var author, commitDate string
+		if err := rows.Scan(&author, &commitDate); err != nil {
+			return 0, 0, 0, nil, err
+		}
+		if detector.Excludes(author) {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, commitDate)
+		if err != nil {
+			continue
+		}
+
+		if t.After(last30) {
+			commits30d++
+		}
+		if t.After(window) {
+			current++
+		} else if t.After(previousWindow) {
+			previous++
+		}
+		if t.After(contributorWindow) {
+			byAuthor[author]++
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return 0, 0, 0, nil, err
+	}
+
+	top := []ContributorCount{}
+	for author, n := range byAuthor {
+		top = append(top, ContributorCount{Author: author, Commits: n})
+	}
+	sort.Slice(top, func(i, j int) bool {
+		if top[i].Commits != top[j].Commits {
+			return top[i].Commits > top[j].Commits
+		}
+		return top[i].Author < top[j].Author
+	})
+	if len(top) > gitsense.PortfolioTopContributors {
+		top = top[:gitsense.PortfolioTopContributors]
+	}
+
+	weeks := float64(gitsense.PortfolioVelocityDays) / 7
+	return commits30d, gitsense.Round(float64(current)/weeks, 1), gitsense.Round(float64(previous)/weeks, 1), top, nil
+}
+
+// ForRepos returns the stored rows of the named repos. Repos synced before
+// portfolio aggregates existed have no row and are refreshed on first use.
+// Rows older than PortfolioStaleHours are returned as they are, marked
+// stale, and refreshed in the background: their time-relative fields
+// (recent commits, velocity, trend, file states) age even when the repo is
+// no longer synced.
+func ForRepos(repoNames []string) ([]Entry, error) {
+	if len(repoNames) == 0 {
+		return []Entry{}, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(repoNames)), ",")
+	args := make([]interface{}, len(repoNames))
+	for i, name := range repoNames {
+		args[i] = name
+	}
+	where := `WHERE repo_name IN (` + placeholders + `)`
+
+	entries, err := query(where, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == len(repoNames) {
+		refreshStale(entries)
+		return entries, nil
+	}
+
+	stored := map[string]bool{}
+	for _, e := range entries {
+		stored[e.Repo] = true
+	}
+	for _, name := range repoNames {
+		if stored[name] {
+			continue
+		}
+		if err := Refresh(name, ""); err != nil {
+			return nil, err
+		}
+	}
+	entries, err = query(where, args...)
+	if err != nil {
+		return nil, err
+	}
+	refreshStale(entries)
+	return entries, nil
+}
+
+// ForOwner returns the stored rows of every repo synced under a GitHub user
+// or org, refreshing stale ones in the background like ForRepos
+func ForOwner(owner string) ([]Entry, error) {
+	entries, err := query(`WHERE owner = ?`, owner)
+	if err != nil {
+		return nil, err
+	}
+	refreshStale(entries)
+	return entries, nil
+}
+
+var (
+	refreshMu  sync.Mutex
+	refreshing = map[string]bool{}
+)
+
+// refreshStale recomputes the stale entries' rows in a background
+// goroutine. A repo already being refreshed is skipped, so repeated reads
+// don't pile up work.
+func refreshStale(entries []Entry) {
+	var stale []string
+	refreshMu.Lock()
+	for _, e := range entries {
+		if e.Stale && !refreshing[e.Repo] {
+			refreshing[e.Repo] = true
+			stale = append(stale, e.Repo)
+		}
+	}
+	refreshMu.Unlock()
+	if len(stale) == 0 {
+		return
+	}
+
+	go func() {
+		for _, name := range stale {
+			if err := Refresh(name, ""); err != nil {
+				fmt.Printf("⚠️  Failed to refresh stale portfolio row for %s: %v\n", name, err)
+			}
+			refreshMu.Lock()
+			delete(refreshing, name)
+			refreshMu.Unlock()
+		}
+	}()
+}
+
+func query(where string, args ...interface{}) ([]Entry, error) {
+	rows, err := db.DB.Query(`
+		SELECT repo_name, owner, total_files, active_files, stable_files, inactive_files,
+			activity_score, project_state, score_change, trend,
+			commits_30d, velocity, previous_velocity, top_contributors, last_synced, updated_at,
+			julianday(updated_at) < julianday('now', ?)
+		FROM repo_stats
+		`+where, append([]interface{}{fmt.Sprintf("-%d hours", gitsense.PortfolioStaleHours)}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []Entry{}
+	for rows.Next() {
+		var e Entry
+		var topJSON string
+		var lastSynced sql.NullString
+		if err := rows.Scan(&e.Repo, &e.Owner, &e.TotalFiles, &e.ActiveFiles, &e.StableFiles, &e.InactiveFiles,
+			&e.ActivityScore, &e.ProjectState, &e.ScoreChange, &e.Trend,
+			&e.Commits30d, &e.Velocity, &e.PreviousVelocity, &topJSON, &lastSynced, &e.UpdatedAt, &e.Stale); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(topJSON), &e.TopContributors); err != nil {
+			e.TopContributors = []ContributorCount{}
+		}
+		e.LastSynced = lastSynced.String
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
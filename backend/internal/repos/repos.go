@@ -6,6 +6,7 @@ import (
 
 	"gitsense"
 	"gitsense/internal/auth"
+	"gitsense/internal/db"
 )
 
 type Repo struct {
@@ -46,3 +47,39 @@ func GetUserRepos(w http.ResponseWriter, r *http.Request) {
 
 	json.NewEncoder(w).Encode(repos)
 }
+
+// IsTracked reports whether the user has synced the repo at least once
+func IsTracked(userID int, repo string) (bool, error) {
+	var count int
+	err := db.DB.QueryRow(`
+		SELECT COUNT(*) FROM user_repos
+		WHERE user_id = ? AND repo_name = ?
+	`, userID, repo).Scan(&count)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// TrackedRepos returns the names of every repo the user has synced
+func TrackedRepos(userID int) ([]string, error) {
+	rows, err := db.DB.Query(`
+		SELECT DISTINCT repo_name FROM user_repos
+		WHERE user_id = ?
+		ORDER BY repo_name
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	return names, rows.Err()
+}
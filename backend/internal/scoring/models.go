@@ -0,0 +1,252 @@
+package scoring
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"gitsense"
+	"gitsense/internal/db"
+	"gitsense/internal/ignore"
+)
+
+// ----------------------------
+// RATIO
+// Share of files changed within the active threshold (the original score)
+// ----------------------------
+type ratioModel struct{}
+
+func (ratioModel) Name() string { return "ratio" }
+
+func (ratioModel) Description() string {
+	return "Share of files changed within the active threshold"
+}
+
+func (ratioModel) Score(in Input) (Result, error) {
+	active := 0
+	for _, age := range in.FileAges {
+		if in.Thresholds.Classify(age) == "active" {
+			active++
+		}
+	}
+
+	share := 0.0
+	if len(in.FileAges) > 0 {
+		share = float64(active) / float64(len(in.FileAges)) * 100
+	}
+
+	return Result{
+		Score: share,
+		Components: []Component{{
+			Name:   "active_share",
+			Value:  float64(active),
+			Score:  gitsense.Round(share, 1),
+			Weight: 1,
+			Detail: fmt.Sprintf("%d of %d files changed in the last %d days", active, len(in.FileAges), in.Thresholds.ActiveDays),
+		}},
+	}, nil
+}
+
+// ----------------------------
+// DECAY
+// Every file contributes by how recently it changed, halving in weight
+// every active-threshold days, instead of the ratio model's hard cut-off
+// ----------------------------
+type decayModel struct{}
+
+func (decayModel) Name() string { return "decay" }
+
+func (decayModel) Description() string {
+	return "Recency-weighted share of files, halving every active-threshold days"
+}
+
+func (decayModel) Score(in Input) (Result, error) {
+	halfLife := float64(in.Thresholds.ActiveDays)
+
+	sum := 0.0
+	for _, age := range in.FileAges {
+		if age < 0 {
+			age = 0
+		}
+		sum += math.Pow(0.5, age/halfLife)
+	}
+
+	score := 0.0
+	if len(in.FileAges) > 0 {
+		score = sum / float64(len(in.FileAges)) * 100
+	}
+
+	return Result{
+		Score: score,
+		Components: []Component{{
+			Name:   "recency",
+			Value:  gitsense.Round(sum, 2),
+			Score:  gitsense.Round(score, 1),
+			Weight: 1,
+			Detail: fmt.Sprintf("%d files, half-life %d days", len(in.FileAges), in.Thresholds.ActiveDays),
+		}},
+	}, nil
+}
+
+// ----------------------------
+// COMPOSITE
+// Weighted blend of commit velocity, contributor diversity, churn and CI
+// health over the last CompositeWindowDays. CI is left out (weight 0) when
+// no runs are recorded.
+// ----------------------------
+type compositeModel struct{}
+
+func (compositeModel) Name() string { return "composite" }
+
+func (compositeModel) Description() string {
+	return "Blend of commit velocity, contributor diversity, churn and CI health"
+}
+
+func (compositeModel) Score(in Input) (Result, error) {
+	windowStart := in.At.AddDate(0, 0, -gitsense.CompositeWindowDays).Format(time.RFC3339)
+	windowEnd := in.At.Format(time.RFC3339)
+	weeks := float64(gitsense.CompositeWindowDays) / 7
+
+	matcher, err := ignore.ForRepo(in.Repo)
+	if err != nil {
+		return Result{}, err
+	}
+
+	// Commits in the window by author
+	rows, err := db.DB.Query(`
+		SELECT author, commit_sha FROM commits
+		WHERE repo_name = ? AND commit_date > ? AND commit_date <= ?
+	`, in.Repo, windowStart, windowEnd)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to load commits: %w", err)
+	}
+	authors := map[string]string{}
+	for rows.Next() {
+		var author, sha string
+		if err := rows.Scan(&author, &sha); err != nil {
+			rows.Close()
+			return Result{}, err
+		}
+		if in.Detector.Excludes(author) {
+			continue
+		}
+		authors[sha] = author
+	}
+	rows.Close()
+
+	// Lines changed by those commits outside ignored paths. Commits that
+	// only touched ignored files (lockfile bumps, vendored updates) don't
+	// count towards velocity or diversity either.
+	rows, err = db.DB.Query(`
+		SELECT commit_sha, file_name, additions + deletions FROM commit_files
+		WHERE repo_name = ? AND commit_sha IN (
+			SELECT commit_sha FROM commits
+			WHERE repo_name = ? AND commit_date > ? AND commit_date <= ?
+		)
+	`, in.Repo, in.Repo, windowStart, windowEnd)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to load churn: %w", err)
+	}
+	churn := 0
+	recorded := map[string]bool{}
+	counted := map[string]bool{}
+	for rows.Next() {
+		var sha, file string
+		var lines int
+		if err := rows.Scan(&sha, &file, &lines); err != nil {
+			rows.Close()
+			return Result{}, err
+		}
+		if _, ok := authors[sha]; !ok {
+			continue
+		}
+		recorded[sha] = true
+		if matcher.Ignored(file) {
+			continue
+		}
+		counted[sha] = true
+		churn += lines
+	}
+	rows.Close()
+
+	// Commits synced without file lists are counted as they are
+	byAuthor := map[string]int{}
+	commits := 0
+	for sha, author := range authors {
+		if recorded[sha] && !counted[sha] {
+			continue
+		}
+		byAuthor[author]++
+		commits++
+	}
+
+	// Effective number of contributors (inverse Simpson index): n equal
+	// contributors count as n, one dominant contributor counts as ~1
+	effective := 0.0
+	if commits > 0 {
+		concentration := 0.0
+		for _, n := range byAuthor {
+			share := float64(n) / float64(commits)
+			concentration += share * share
+		}
+		effective = 1 / concentration
+	}
+
+	// CI success rate over completed runs in the window
+	var succeeded, completed int
+	err = db.DB.QueryRow(`
+		SELECT COALESCE(SUM(CASE WHEN conclusion = 'success' THEN 1 ELSE 0 END), 0),
+			COUNT(*)
+		FROM ci_runs
+		WHERE repo_name = ? AND created_at > ? AND created_at <= ?
+		  AND conclusion IN ('success', 'failure', 'timed_out', 'startup_failure')
+	`, in.Repo, windowStart, windowEnd).Scan(&succeeded, &completed)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to load CI runs: %w", err)
+	}
+
+	velocity := float64(commits) / weeks
+	churnPerWeek := float64(churn) / weeks
+
+	components := []Component{
+		{
+			Name:   "velocity",
+			Value:  gitsense.Round(velocity, 1),
+			Score:  gitsense.Round(saturate(velocity, gitsense.CompositeVelocityScale), 1),
+			Weight: gitsense.CompositeVelocityWeight,
+			Detail: fmt.Sprintf("%d commits in %d days", commits, gitsense.CompositeWindowDays),
+		},
+		{
+			Name:   "contributor_diversity",
+			Value:  gitsense.Round(effective, 2),
+			Score:  gitsense.Round(math.Min(effective/gitsense.CompositeDiversityTarget, 1)*100, 1),
+			Weight: gitsense.CompositeDiversityWeight,
+			Detail: fmt.Sprintf("%d contributors, %.1f effective", len(byAuthor), effective),
+		},
+		{
+			Name:   "churn",
+			Value:  gitsense.Round(churnPerWeek, 1),
+			Score:  gitsense.Round(saturate(churnPerWeek, gitsense.CompositeChurnScale), 1),
+			Weight: gitsense.CompositeChurnWeight,
+			Detail: fmt.Sprintf("%d lines changed in %d days", churn, gitsense.CompositeWindowDays),
+		},
+	}
+
+	ci := Component{Name: "ci_health", Detail: "no CI runs recorded"}
+	if completed > 0 {
+		rate := float64(succeeded) / float64(completed) * 100
+		ci.Value = gitsense.Round(rate, 1)
+		ci.Score = gitsense.Round(rate, 1)
+		ci.Weight = gitsense.CompositeCIWeight
+		ci.Detail = fmt.Sprintf("%d of %d runs succeeded", succeeded, completed)
+	}
+	components = append(components, ci)
+
+	return Result{Score: weighted(components), Components: components}, nil
+}
+
+// saturate maps a non-negative value onto 0-100 with diminishing returns;
+// value == scale scores about 63
+func saturate(value, scale float64) float64 {
+	return (1 - math.Exp(-value/scale)) * 100
+}
@@ -0,0 +1,131 @@
+package scoring
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gitsense"
+	"gitsense/internal/bots"
+	"gitsense/internal/settings"
+)
+
+// Input is what a model scores: a repo's file ages at a reference time, plus
+// what models need to load their own data (commits, churn, CI runs)
+type Input struct {
+	Repo       string
+	Detector   *bots.Detector
+	At         time.Time
+	Thresholds settings.Thresholds
+	FileAges   []float64 // days since each file's last change, at At
+}
+
+// Component is one ingredient of a score. Value is the raw measurement,
+// Score its 0-100 rating and Weight its share of the final score.
+type Component struct {
+	Name   string  `json:"name"`
+	Value  float64 `json:"value"`
+	Score  float64 `json:"score"`
+	Weight float64 `json:"weight"`
+	Detail string  `json:"detail,omitempty"`
+}
+
+// Result is a model's score with the components it was built from
+type Result struct {
+	Model      string      `json:"model"`
+	Score      float64     `json:"score"`
+	State      string      `json:"state"`
+	Components []Component `json:"components"`
+}
+
+// Model computes a 0-100 health score for a repo
+type Model interface {
+	Name() string
+	Description() string
+	Score(in Input) (Result, error)
+}
+
+var models = map[string]Model{}
+
+// Register makes a model selectable by name
+func Register(m Model) {
+	models[m.Name()] = m
+}
+
+func init() {
+	Register(ratioModel{})
+	Register(decayModel{})
+	Register(compositeModel{})
+}
+
+// Get returns the model registered under name
+func Get(name string) (Model, error) {
+	m, ok := models[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown score model: %s (available: %s)", name, strings.Join(Names(), ", "))
+	}
+	return m, nil
+}
+
+// Names lists the registered models, sorted
+func Names() []string {
+	names := make([]string, 0, len(models))
+	for name := range models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ForRepo returns the model configured for a repo, falling back to the
+// default when the setting is missing or names an unknown model
+func ForRepo(repo string) Model {
+	name, err := settings.GetScoreModel(repo)
+	if err != nil {
+		fmt.Printf("⚠️  %v (repo: %s), using %s\n", err, repo, settings.DefaultScoreModel)
+	}
+	m, err := Get(name)
+	if err != nil {
+		m, _ = Get(settings.DefaultScoreModel)
+	}
+	return m
+}
+
+// Evaluate scores the input with a model, filling in the state label
+func Evaluate(m Model, in Input) (Result, error) {
+	if in.At.IsZero() {
+		in.At = time.Now().UTC()
+	}
+	result, err := m.Score(in)
+	if err != nil {
+		return result, err
+	}
+	result.Model = m.Name()
+	result.Score = gitsense.Round(result.Score, 0)
+	result.State = State(result.Score)
+	return result, nil
+}
+
+// State maps a score to the project state label
+func State(score float64) string {
+	if score > 50 {
+		return "HIGH ACTIVITY"
+	} else if score > 25 {
+		return "EVOLVING"
+	}
+	return "STABLE"
+}
+
+// weighted combines component scores by weight, ignoring zero-weight components
+func weighted(components []Component) float64 {
+	total, weights := 0.0, 0.0
+	for _, c := range components {
+		total += c.Score * c.Weight
+		weights += c.Weight
+	}
+	if weights == 0 {
+		return 0
+	}
+	return total / weights
+}
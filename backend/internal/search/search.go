@@ -0,0 +1,173 @@
+package search
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"gitsense"
+	"gitsense/internal/bots"
+	"gitsense/internal/db"
+)
+
+// Highlight markers around matched terms in snippets and paths
+const (
+	MarkStart = "<mark>"
+	MarkEnd   = "</mark>"
+)
+
+// FTS5 wraps matches in these private-use characters, which can't be
+// confused with markup in the commit text; they become the marks above once
+// the text around them is escaped
+const (
+	sentinelStart = "\uE000"
+	sentinelEnd   = "\uE001"
+)
+
+// Query is a commit search over one or more repos
+type Query struct {
+	Text      string
+	Repos     []string
+	Author    string
+	Range     gitsense.TimeRange
+	Limit     int
+	Detectors map[string]*bots.Detector // per repo; nil excludes nobody
+}
+
+// Result is one matching commit. Snippet is the message with matched terms
+// marked; Paths lists the touched files that matched, also marked. Both are
+// HTML-escaped apart from the marks, so they can be rendered as HTML.
+type Result struct {
+	Repo    string   `json:"repo"`
+	SHA     string   `json:"sha"`
+	Author  string   `json:"author"`
+	Date    string   `json:"date"`
+	Message string   `json:"message"`
+	Snippet string   `json:"snippet"`
+	Paths   []string `json:"matched_paths"`
+	Score   float64  `json:"score"`
+}
+
+// Run returns the best-ranked matches, up to q.Limit, and the total number
+// of matches. Messages weigh most, then paths, then authors.
+func Run(q Query) ([]Result, int, error) {
+	match, err := MatchExpression(q.Text)
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(q.Repos) == 0 {
+		return []Result{}, 0, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(q.Repos)), ",")
+	from, to := q.Range.Bounds()
+	args := []interface{}{sentinelStart, sentinelEnd, sentinelStart, sentinelEnd, match}
+	for _, repo := range q.Repos {
+		args = append(args, repo)
+	}
+	args = append(args, q.Author, q.Author, from, to)
+
+	rows, err := db.DB.Query(`
+		SELECT c.repo_name, c.commit_sha, c.author, c.message, c.commit_date,
+			snippet(commits_fts, 0, ?, ?, '…', `+fmt.Sprint(gitsense.SearchSnippetTokens)+`),
+			highlight(commits_fts, 2, ?, ?),
+			bm25(commits_fts, 10.0, 2.0, 5.0) AS rank
+		FROM commits_fts
+		JOIN commits c ON c.id = commits_fts.rowid
+		WHERE commits_fts MATCH ?
+		  AND c.repo_name IN (`+placeholders+`)
+		  AND (? = '' OR c.author = ?)
+		  AND c.commit_date >= ? AND c.commit_date <= ?
+		ORDER BY rank, c.commit_date DESC
+	`, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("search failed: %w", err)
+	}
+	defer rows.Close()
+
+	results := []Result{}
+	total := 0
+	for rows.Next() {
+		var r Result
+		var paths string
+		var rank float64
+		if err := rows.Scan(&r.Repo, &r.SHA, &r.Author, &r.Message, &r.Date, &r.Snippet, &paths, &rank); err != nil {
+			return nil, 0, err
+		}
+		if q.Detectors[r.Repo].Excludes(r.Author) {
+			continue
+		}
+		total++
+		if len(results) >= q.Limit {
+			continue
+		}
+
+		r.Paths = []string{}
+		for _, p := range strings.Fields(paths) {
+			if strings.Contains(p, sentinelStart) {
+				r.Paths = append(r.Paths, markup(p))
+			}
+		}
+		if strings.Contains(r.Snippet, sentinelStart) {
+			r.Snippet = markup(r.Snippet)
+		} else {
+			r.Snippet = ""
+		}
+		// bm25 is lower for better matches
+		r.Score = float64(int(-rank*1000+0.5)) / 1000
+		results = append(results, r)
+	}
+	return results, total, rows.Err()
+}
+
+// markup HTML-escapes highlighted text and turns its sentinels into marks
+func markup(text string) string {
+	text = html.EscapeString(text)
+	text = strings.ReplaceAll(text, sentinelStart, MarkStart)
+	return strings.ReplaceAll(text, sentinelEnd, MarkEnd)
+}
+
+// MatchExpression turns a user query into an FTS5 expression. Words and
+// "quoted phrases" must all match; a trailing * matches word prefixes.
+// FTS5 operators are not interpreted, so any input is safe to search.
+func MatchExpression(text string) (string, error) {
+	var terms []string
+	var current strings.Builder
+	inPhrase := false
+
+	flush := func(prefix bool) {
+		term := strings.TrimSpace(current.String())
+		current.Reset()
+		if term == "" {
+			return
+		}
+		quoted := `"` + strings.ReplaceAll(term, `"`, `""`) + `"`
+		if prefix {
+			quoted += "*"
+		}
+		terms = append(terms, quoted)
+	}
+
+	runes := []rune(text)
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case c == '"':
+			flush(false)
+			inPhrase = !inPhrase
+		case c == '*' && !inPhrase && (i+1 == len(runes) || unicode.IsSpace(runes[i+1])):
+			flush(true)
+		case unicode.IsSpace(c) && !inPhrase:
+			flush(false)
+		default:
+			current.WriteRune(c)
+		}
+	}
+	flush(false)
+
+	if len(terms) == 0 {
+		return "", fmt.Errorf("search query must contain at least one word")
+	}
+	return strings.Join(terms, " "), nil
+}
@@ -0,0 +1,50 @@
+package search
+
+import "testing"
+
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		text    string
+		want    string
+		wantErr bool
+	}{
+		{"retry", `"retry"`, false},
+		{"retry logic", `"retry" "logic"`, false},
+		{`"retry logic" db`, `"retry logic" "db"`, false},
+		{"retr*", `"retr"*`, false},
+		{"retr* db", `"retr"* "db"`, false},
+		{"a*b", `"a*b"`, false},
+		{"fix OR NOT NEAR(x)", `"fix" "OR" "NOT" "NEAR(x)"`, false},
+		{`say "hi`, `"say" "hi"`, false},
+		{`quote"inside`, `"quote" "inside"`, false},
+		{"  ", "", true},
+		{`""`, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := MatchExpression(tt.text)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("MatchExpression(%q): err = %v, want error %v", tt.text, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("MatchExpression(%q) = %s, want %s", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestMarkup(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"fix " + sentinelStart + "retry" + sentinelEnd + " logic", "fix <mark>retry</mark> logic"},
+		{"<script>" + sentinelStart + "x" + sentinelEnd, "&lt;script&gt;<mark>x</mark>"},
+		{"<mark>" + sentinelStart + "y" + sentinelEnd + "</mark> & z", "&lt;mark&gt;<mark>y</mark>&lt;/mark&gt; &amp; z"},
+	}
+	for _, tt := range tests {
+		if got := markup(tt.text); got != tt.want {
+			t.Errorf("markup(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
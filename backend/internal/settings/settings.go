@@ -0,0 +1,223 @@
+package settings
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gitsense"
+	"gitsense/internal/db"
+)
+
+// Thresholds controls how files are classified by days since last modification.
+// Files modified within ActiveDays are active, within StableDays are stable,
+// and anything older is inactive.
+type Thresholds struct {
+	ActiveDays int `json:"active_days"`
+	StableDays int `json:"stable_days"`
+}
+
+// DefaultThresholds returns the thresholds used when a repo has no settings
+func DefaultThresholds() Thresholds {
+	return Thresholds{
+		ActiveDays: gitsense.ActiveThreshold,
+		StableDays: gitsense.StableThreshold,
+	}
+}
+
+// Validate checks that the thresholds are positive and correctly ordered
+func (t Thresholds) Validate() error {
+	if t.ActiveDays < 1 {
+		return fmt.Errorf("active_days must be at least 1")
+	}
+	if t.StableDays <= t.ActiveDays {
+		return fmt.Errorf("stable_days must be greater than active_days")
+	}
+	if t.StableDays > gitsense.MaxThresholdDays {
+		return fmt.Errorf("stable_days exceeds maximum of %d", gitsense.MaxThresholdDays)
+	}
+	return nil
+}
+
+// Classify returns "active", "stable" or "inactive" for the given file age
+func (t Thresholds) Classify(daysSinceModified float64) string {
+	if daysSinceModified <= float64(t.ActiveDays) {
+		return "active"
+	}
+	if daysSinceModified <= float64(t.StableDays) {
+		return "stable"
+	}
+	return "inactive"
+}
+
+// GetThresholds returns the thresholds configured for a repo, falling back to defaults
+func GetThresholds(repo string) (Thresholds, error) {
+	var t Thresholds
+	err := db.DB.QueryRow(`
+		SELECT active_threshold, stable_threshold
+		FROM repo_settings
+		WHERE repo_name = ?
+	`, repo).Scan(&t.ActiveDays, &t.StableDays)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return DefaultThresholds(), nil
+	}
+	if err != nil {
+		return DefaultThresholds(), fmt.Errorf("failed to load thresholds: %w", err)
+	}
+	return t, nil
+}
+
+// SaveThresholds stores the thresholds for a repo
+func SaveThresholds(repo string, t Thresholds) error {
+	if err := t.Validate(); err != nil {
+		return err
+	}
+
+	_, err := db.DB.Exec(`
+		INSERT INTO repo_settings (repo_name, active_threshold, stable_threshold, updated_at)
+		VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(repo_name)
+		DO UPDATE SET
+			active_threshold = excluded.active_threshold,
+			stable_threshold = excluded.stable_threshold,
+			updated_at = CURRENT_TIMESTAMP
+	`, repo, t.ActiveDays, t.StableDays)
+	if err != nil {
+		return fmt.Errorf("failed to save thresholds: %w", err)
+	}
+	return nil
+}
+
+// BotSettings controls whether bot commits count towards a repo's analytics.
+// BotAuthors extends the built-in bot detection with repo-specific names.
+type BotSettings struct {
+	IncludeBots bool     `json:"include_bots"`
+	BotAuthors  []string `json:"bot_authors"`
+}
+
+// DefaultBotSettings returns the bot settings used when a repo has no settings
+func DefaultBotSettings() BotSettings {
+	return BotSettings{IncludeBots: true, BotAuthors: []string{}}
+}
+
+// Validate checks the configured bot author list
+func (b BotSettings) Validate() error {
+	if len(b.BotAuthors) > gitsense.MaxBotAuthors {
+		return fmt.Errorf("bot_authors exceeds maximum of %d names", gitsense.MaxBotAuthors)
+	}
+	for _, name := range b.BotAuthors {
+		if strings.TrimSpace(name) == "" || len(name) > 100 {
+			return fmt.Errorf("bot_authors entries must be 1-100 characters")
+		}
+	}
+	return nil
+}
+
+// GetBotSettings returns the bot settings configured for a repo, falling back to defaults
+func GetBotSettings(repo string) (BotSettings, error) {
+	var includeBots int
+	var authors string
+	err := db.DB.QueryRow(`
+		SELECT include_bots, bot_authors
+		FROM repo_settings
+		WHERE repo_name = ?
+	`, repo).Scan(&includeBots, &authors)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return DefaultBotSettings(), nil
+	}
+	if err != nil {
+		return DefaultBotSettings(), fmt.Errorf("failed to load bot settings: %w", err)
+	}
+
+	b := BotSettings{IncludeBots: includeBots != 0, BotAuthors: []string{}}
+	for _, name := range strings.Split(authors, "\n") {
+		if name = strings.TrimSpace(name); name != "" {
+			b.BotAuthors = append(b.BotAuthors, name)
+		}
+	}
+	return b, nil
+}
+
+// SaveBotSettings stores the bot settings for a repo
+func SaveBotSettings(repo string, b BotSettings) error {
+	if err := b.Validate(); err != nil {
+		return err
+	}
+
+	includeBots := 0
+	if b.IncludeBots {
+		includeBots = 1
+	}
+	defaults := DefaultThresholds()
+
+	_, err := db.DB.Exec(`
+		INSERT INTO repo_settings (repo_name, active_threshold, stable_threshold, include_bots, bot_authors, updated_at)
+		VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(repo_name)
+		DO UPDATE SET
+			include_bots = excluded.include_bots,
+			bot_authors = excluded.bot_authors,
+			updated_at = CURRENT_TIMESTAMP
+	`, repo, defaults.ActiveDays, defaults.StableDays, includeBots, strings.Join(b.BotAuthors, "\n"))
+	if err != nil {
+		return fmt.Errorf("failed to save bot settings: %w", err)
+	}
+	return nil
+}
+
+// DefaultScoreModel is the health scoring model used when a repo has not chosen one
+const DefaultScoreModel = "ratio"
+
+// GetScoreModel returns the name of the health scoring model configured for a repo
+func GetScoreModel(repo string) (string, error) {
+	var model string
+	err := db.DB.QueryRow(`
+		SELECT score_model FROM repo_settings WHERE repo_name = ?
+	`, repo).Scan(&model)
+
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && model == "") {
+		return DefaultScoreModel, nil
+	}
+	if err != nil {
+		return DefaultScoreModel, fmt.Errorf("failed to load score model: %w", err)
+	}
+	return model, nil
+}
+
+// SaveScoreModel stores the health scoring model for a repo. The name is
+// validated by the caller against the registered models.
+func SaveScoreModel(repo, model string) error {
+	defaults := DefaultThresholds()
+
+	_, err := db.DB.Exec(`
+		INSERT INTO repo_settings (repo_name, active_threshold, stable_threshold, score_model, updated_at)
+		VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(repo_name)
+		DO UPDATE SET
+			score_model = excluded.score_model,
+			updated_at = CURRENT_TIMESTAMP
+	`, repo, defaults.ActiveDays, defaults.StableDays, model)
+	if err != nil {
+		return fmt.Errorf("failed to save score model: %w", err)
+	}
+	return nil
+}
+
+// ThresholdCache memoizes thresholds for handlers that classify files across many repos
+type ThresholdCache map[string]Thresholds
+
+// Get returns the cached thresholds for a repo, loading them on first use
+func (c ThresholdCache) Get(repo string) Thresholds {
+	if t, ok := c[repo]; ok {
+		return t
+	}
+	t, err := GetThresholds(repo)
+	if err != nil {
+		fmt.Printf("⚠️  %v (repo: %s), using defaults\n", err, repo)
+	}
+	c[repo] = t
+	return t
+}
@@ -7,9 +7,16 @@ import (
 	"time"
 
 	"gitsense"
+	"gitsense/internal/activity"
+	"gitsense/internal/anomaly"
 	"gitsense/internal/auth"
+	"gitsense/internal/bots"
 	"gitsense/internal/db"
 	githubapi "gitsense/internal/github"
+	"gitsense/internal/ignore"
+	"gitsense/internal/portfolio"
+	"gitsense/internal/scoring"
+	"gitsense/internal/settings"
 )
 
 func SyncHandler(w http.ResponseWriter, r *http.Request) {
@@ -74,6 +81,9 @@ func SyncHandler(w http.ResponseWriter, r *http.Request) {
 
 	newCommits := after - before
 
+	// Refresh ignore rules from the repo's .gitsense.yml, if any
+	rulesChanged := syncIgnoreConfig(owner, repo, githubToken)
+
 	// Save repo under user
 	db.DB.Exec(`
 		INSERT INTO user_repos (user_id, repo_name, last_synced)
@@ -97,6 +107,16 @@ func SyncHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	} else {
+		// Stored snapshots were counted under the previous ignore rules
+		if rulesChanged {
+			fmt.Println("🙈 Ignore rules changed - recomputing snapshots...")
+			if _, err := RecomputeSnapshots(repo); err != nil {
+				fmt.Printf("⚠️  Failed to recompute snapshots: %v\n", err)
+				http.Error(w, "Snapshot recomputation failed", http.StatusInternalServerError)
+				return
+			}
+		}
+
 		// Only create snapshot if there are new commits OR no snapshot for today exists
 		if newCommits > 0 {
 			fmt.Println("📊 Creating snapshot for new commits...")
@@ -126,6 +146,18 @@ func SyncHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	// Flag unusual activity (spikes, droughts, silent contributors)
+	if found, err := anomaly.DetectAndStore(repo, bots.ForRepo(repo)); err != nil {
+		fmt.Printf("⚠️  Anomaly detection failed: %v\n", err)
+	} else if found > 0 {
+		fmt.Printf("🚨 %d anomalies detected for '%s'\n", found, repo)
+	}
+
+	// Precompute the repo's portfolio row
+	if err := portfolio.Refresh(repo, owner); err != nil {
+		fmt.Printf("⚠️  Failed to refresh portfolio stats: %v\n", err)
+	}
+
 	// Notify if new commits exist
 	if newCommits > 0 {
 		msg := fmt.Sprintf("🔔 %d new commit(s) detected", newCommits)
@@ -144,32 +176,14 @@ func saveSnapshot(repo string) error {
 }
 
 func saveSnapshotForDate(repo string, referenceDate string) error {
-	dateExpr := "julianday('now')"
-	if referenceDate != "" {
-		dateExpr = fmt.Sprintf("julianday('%s')", referenceDate)
+	thresholds, err := settings.GetThresholds(repo)
+	if err != nil {
+		return err
 	}
 
-	row := db.DB.QueryRow(fmt.Sprintf(`
-		SELECT
-			SUM(CASE WHEN %s - julianday(last_modified) <= %d THEN 1 ELSE 0 END),
-			SUM(CASE WHEN %s - julianday(last_modified) BETWEEN %d AND %d THEN 1 ELSE 0 END),
-			SUM(CASE WHEN %s - julianday(last_modified) > %d THEN 1 ELSE 0 END)
-		FROM file_activity
-		WHERE repo_name = ?
-	`, dateExpr, gitsense.ActiveThreshold, dateExpr, gitsense.ActiveThreshold, gitsense.StableThreshold, dateExpr, gitsense.InactiveThreshold), repo)
-
-	var active, stable, inactive int
-	row.Scan(&active, &stable, &inactive)
-
-	total := active + stable + inactive
-	score := 0.0
-	if total > 0 {
-		rawScore := (float64(active) / float64(total)) * 100
-		score = float64(int(rawScore + 0.5)) // Round to nearest integer
-	}
+	active, stable, inactive, score := snapshotState(repo, referenceDate, thresholds)
 
 	// Retry logic for SQLITE_BUSY errors
-	var err error
 	retryDelay := gitsense.InitialRetryDelay
 
 	for attempt := 0; attempt < gitsense.MaxDBRetries; attempt++ {
@@ -214,6 +228,150 @@ func saveSnapshotForDate(repo string, referenceDate string) error {
 	return nil
 }
 
+// snapshotState classifies a repo's files relative to referenceDate ("" means now),
+// honoring its ignore rules and bot settings, and scores them with the repo's model
+func snapshotState(repo string, referenceDate string, t settings.Thresholds) (active, stable, inactive int, score float64) {
+	reference := time.Now().UTC()
+	if referenceDate != "" {
+		parsed, err := parseSnapshotDate(referenceDate)
+		if err != nil {
+			fmt.Printf("⚠️  Invalid snapshot date %q: %v\n", referenceDate, err)
+			return 0, 0, 0, 0
+		}
+		reference = parsed
+	}
+
+	detector := bots.ForRepo(repo)
+	files, err := activity.LoadFileStats(repo, detector)
+	if err != nil {
+		fmt.Printf("⚠️  Failed to load file activity for '%s': %v\n", repo, err)
+		return 0, 0, 0, 0
+	}
+
+	var ages []float64
+	for _, f := range files {
+		lastModified, err := time.Parse(time.RFC3339, f.LastModified)
+		if err != nil {
+			continue
+		}
+
+		age := reference.Sub(lastModified).Hours() / 24
+		ages = append(ages, age)
+
+		switch t.Classify(age) {
+		case "active":
+			active++
+		case "stable":
+			stable++
+		default:
+			inactive++
+		}
+	}
+
+	result, err := scoring.Evaluate(scoring.ForRepo(repo), scoring.Input{
+		Repo:       repo,
+		Detector:   detector,
+		At:         reference,
+		Thresholds: t,
+		FileAges:   ages,
+	})
+	if err != nil {
+		fmt.Printf("⚠️  Failed to score '%s': %v\n", repo, err)
+	}
+	return active, stable, inactive, result.Score
+}
+
+// parseSnapshotDate parses snapshot timestamps as written by SQLite or GitHub
+func parseSnapshotDate(value string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05", time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognized date format")
+}
+
+// syncIgnoreConfig replaces the repo's config-sourced ignore rules with the
+// contents of its .gitsense.yml and reports whether they changed. Failures
+// (including a config over MaxRepoConfigBytes) are logged, keep the stored
+// rules and never fail the sync.
+func syncIgnoreConfig(owner, repo, githubToken string) bool {
+	data, err := githubapi.FetchRepoFile(owner, repo, ignore.ConfigFileName, githubToken)
+	if err != nil {
+		fmt.Printf("⚠️  Failed to fetch %s: %v\n", ignore.ConfigFileName, err)
+		return false
+	}
+
+	patterns, err := ignore.ParseConfig(data)
+	if err != nil {
+		fmt.Printf("⚠️  Invalid %s: %v\n", ignore.ConfigFileName, err)
+		return false
+	}
+
+	changed, err := ignore.ReplaceRules(repo, ignore.SourceConfig, patterns)
+	if err != nil {
+		fmt.Printf("⚠️  Failed to save ignore rules: %v\n", err)
+		return false
+	}
+	if len(patterns) > 0 {
+		fmt.Printf("🙈 Loaded %d ignore rule(s) from %s\n", len(patterns), ignore.ConfigFileName)
+	}
+	return changed
+}
+
+// RecomputeSnapshots re-classifies and re-scores every stored snapshot of a
+// repo using its current thresholds and score model. Snapshot dates are kept so /history stays comparable.
+func RecomputeSnapshots(repo string) (int, error) {
+	thresholds, err := settings.GetThresholds(repo)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := db.DB.Query(`
+		SELECT id, created_at
+		FROM repo_snapshots
+		WHERE repo_name = ?
+		ORDER BY created_at ASC
+	`, repo)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query snapshots: %w", err)
+	}
+
+	type snapshotRef struct {
+		id        int
+		createdAt string
+	}
+	var refs []snapshotRef
+	for rows.Next() {
+		var ref snapshotRef
+		if err := rows.Scan(&ref.id, &ref.createdAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan snapshot: %w", err)
+		}
+		refs = append(refs, ref)
+	}
+	rows.Close()
+
+	for _, ref := range refs {
+		active, stable, inactive, score := snapshotState(repo, ref.createdAt, thresholds)
+		_, err := db.DB.Exec(`
+			UPDATE repo_snapshots
+			SET active_files = ?, stable_files = ?, inactive_files = ?, activity_score = ?
+			WHERE id = ?
+		`, active, stable, inactive, score, ref.id)
+		if err != nil {
+			return 0, fmt.Errorf("failed to update snapshot %d: %w", ref.id, err)
+		}
+	}
+
+	fmt.Printf("♻️  Recomputed %d snapshots for '%s'\n", len(refs), repo)
+
+	if err := portfolio.Refresh(repo, ""); err != nil {
+		fmt.Printf("⚠️  Failed to refresh portfolio stats: %v\n", err)
+	}
+	return len(refs), nil
+}
+
 // ----------------------------
 // HISTORICAL SNAPSHOTS (FROM COMMITS)
 // ----------------------------
@@ -9,6 +9,10 @@ let contributionChart = null;
 let latestHistory = [];
 let latestFiles = [];
 let latestBreakdown = {};
+let commitsCursor = null;
+let commitsTotal = 0;
+let filesTotal = 0;
+const commitDetails = {};
 
 const state = {
   timeRangeDays: 30,
@@ -65,6 +69,11 @@ function setupControls() {
     }, 150);
   });
 
+  document.getElementById("commitTableBody").addEventListener("click", (e) => {
+    const row = e.target.closest("tr.commit-row");
+    if (row && !e.target.closest("a")) toggleCommitDetail(row);
+  });
+
   refreshEl.addEventListener("click", () => {
     setPageStatus("Refreshing dashboard data...", "info");
     loadDashboardData(repo, true);
@@ -220,21 +229,65 @@ async function fetchJSONOrThrow(url) {
   return res.json();
 }
 
+// Fetches one page of a paginated endpoint, continuing from cursor if given
+async function fetchPage(url, cursor = null) {
+  const pageURL = cursor ? `${url}&cursor=${encodeURIComponent(cursor)}` : url;
+  return fetchJSONOrThrow(pageURL);
+}
+
+function commitsURL() {
+  const repoName = repo.split("/")[1];
+  return `${API_BASE_URL}/commits?repo=${repoName}&limit=100`;
+}
+
+// Appends the next page of commits when the user asks for more
+async function loadMoreCommits() {
+  if (!commitsCursor) return;
+  const button = document.getElementById("loadMoreCommits");
+  button.disabled = true;
+  button.textContent = "Loading...";
+
+  try {
+    const page = await fetchPage(commitsURL(), commitsCursor);
+    allCommits = allCommits.concat(Array.isArray(page.commits) ? page.commits : []);
+    commitsCursor = page.next_cursor;
+    allData.commits = allCommits;
+    applyCommitFiltersAndRender();
+  } catch (err) {
+    console.error("Failed to load more commits:", err);
+    setPageStatus("Unable to load more commits. Check backend and retry.", "error");
+  } finally {
+    button.disabled = false;
+    updateLoadMoreButton();
+  }
+}
+
+function updateLoadMoreButton() {
+  const button = document.getElementById("loadMoreCommits");
+  if (!button) return;
+  button.classList.toggle("hidden", !commitsCursor);
+  button.textContent = `Load more (${allCommits.length} of ${commitsTotal})`;
+}
+
 async function loadDashboardData(repoNameWithOwner, isRefresh = false) {
   const repoName = repoNameWithOwner.split("/")[1];
   setLoading(true, isRefresh ? "Refreshing data..." : "Loading dashboard data...");
 
   try {
-    const [history, commits, files, fileBreakdown] = await Promise.all([
+    // Only the first page of each; more commits are fetched on demand
+    const [history, commitsPage, filesPage, fileBreakdown] = await Promise.all([
       fetchJSONOrThrow(`${API_BASE_URL}/history?repo=${repoName}`),
-      fetchJSONOrThrow(`${API_BASE_URL}/commits?repo=${repoName}&limit=100`),
-      fetchJSONOrThrow(`${API_BASE_URL}/files?repo=${repoName}`),
+      fetchPage(commitsURL()),
+      fetchPage(`${API_BASE_URL}/files?repo=${repoName}&limit=1000`),
       fetchJSONOrThrow(`${API_BASE_URL}/file-breakdown?repo=${repoName}`)
     ]);
 
     latestHistory = history || [];
-    allCommits = Array.isArray(commits) ? commits : [];
-    latestFiles = Array.isArray(files) ? files : [];
+    allCommits = Array.isArray(commitsPage.commits) ? commitsPage.commits : [];
+    commitsCursor = commitsPage.next_cursor;
+    commitsTotal = commitsPage.total || allCommits.length;
+    latestFiles = Array.isArray(filesPage.files) ? filesPage.files : [];
+    filesTotal = filesPage.total || latestFiles.length;
     latestBreakdown = fileBreakdown || {};
 
     allData = {
@@ -245,8 +298,8 @@ async function loadDashboardData(repoNameWithOwner, isRefresh = false) {
         query: state.query
       },
       stats: {
-        totalCommits: allCommits.length,
-        totalFiles: latestFiles.length,
+        totalCommits: commitsTotal,
+        totalFiles: filesTotal,
         activeFiles: latestFiles.filter((f) => f.status === "active").length
       },
       commits: allCommits,
@@ -279,6 +332,7 @@ function applyCommitFiltersAndRender() {
   renderCommitsPerDay(filteredCommits);
   renderContributionDistribution(filteredCommits);
   renderCommitTable(currentPage);
+  updateLoadMoreButton();
 }
 
 function applyCommitFilters(commits, timeRangeDays, query) {
@@ -350,11 +404,11 @@ function updateStats(history, visibleCommits, totalCommits, files) {
 
   totalCommitsEl.textContent = visibleCommits?.length || 0;
   document.getElementById("totalCommitsSub").textContent =
-    `${totalCommits?.length || 0} total in repository`;
+    `${commitsTotal || totalCommits?.length || 0} total in repository`;
 
   const activeCount = files ? files.filter((f) => f.status === "active").length : 0;
   activeFilesEl.textContent = activeCount;
-  totalFilesEl.textContent = files?.length || 0;
+  totalFilesEl.textContent = filesTotal || files?.length || 0;
 
   document.getElementById("activeFilesSub").textContent = "Modified in last 7 days";
   document.getElementById("totalFilesSub").textContent = "Tracked by sync history";
@@ -641,7 +695,7 @@ function renderCommitTable(page) {
       const sha = (c.sha || "N/A").substring(0, 7);
 
       return `
-      <tr>
+      <tr class="commit-row" data-sha="${escapeHTML(c.sha || "")}">
         <td><span class="commit-sha">${sha}</span></td>
         <td>${dateStr} ${timeStr}<div class="table-subtext">${formatRelativeTime(c.date)}</div></td>
         <td>${highlightMatch(c.author || "Unknown", state.query)}</td>
@@ -656,6 +710,66 @@ function renderCommitTable(page) {
   document.getElementById("nextPage").disabled = page >= totalPages;
 }
 
+// Expands a commit row into the files it changed and its parent commits
+async function toggleCommitDetail(row) {
+  const next = row.nextElementSibling;
+  if (next && next.classList.contains("commit-detail-row")) {
+    next.remove();
+    return;
+  }
+
+  const sha = row.dataset.sha;
+  const detailRow = document.createElement("tr");
+  detailRow.className = "commit-detail-row";
+  detailRow.innerHTML = '<td colspan="4" class="table-subtext">Loading changed files...</td>';
+  row.after(detailRow);
+
+  try {
+    if (!commitDetails[sha]) {
+      const repoName = repo.split("/")[1];
+      commitDetails[sha] = await fetchJSONOrThrow(
+        `${API_BASE_URL}/commits/detail?repo=${encodeURIComponent(repoName)}&sha=${encodeURIComponent(sha)}`
+      );
+    }
+    detailRow.innerHTML = `<td colspan="4">${renderCommitDetail(commitDetails[sha])}</td>`;
+  } catch (err) {
+    console.error("Failed to load commit detail:", err);
+    detailRow.innerHTML = '<td colspan="4" class="table-subtext">Unable to load changed files.</td>';
+  }
+}
+
+function renderCommitDetail(detail) {
+  const files = (detail.files || [])
+    .map((f) => {
+      const status = f.status || "modified";
+      const renamed = f.previous_name ? `<span class="table-subtext"> ← ${escapeHTML(f.previous_name)}</span>` : "";
+      return `
+        <li class="commit-file">
+          <span class="file-status ${escapeHTML(status)}">${escapeHTML(status)}</span>
+          <span class="file-name">${escapeHTML(f.name)}</span>${renamed}
+          <span class="line-stats"><span class="added">+${f.additions}</span> <span class="removed">-${f.deletions}</span></span>
+        </li>`;
+    })
+    .join("");
+
+  const parents = (detail.parents || [])
+    .map((p) => {
+      const label = `<span class="commit-sha">${escapeHTML(p.sha.substring(0, 7))}</span>`;
+      return p.html_url ? `<a href="${escapeHTML(p.html_url)}" target="_blank" rel="noopener">${label}</a>` : label;
+    })
+    .join(" ");
+
+  return `
+    <div class="commit-detail">
+      <div class="table-subtext">
+        ${escapeHTML(detail.author)}${detail.author_email ? ` &lt;${escapeHTML(detail.author_email)}&gt;` : ""}
+        · <span class="added">+${detail.additions}</span> <span class="removed">-${detail.deletions}</span>
+        ${parents ? ` · parents ${parents}` : ""}
+      </div>
+      ${files ? `<ul class="commit-files">${files}</ul>` : '<div class="table-subtext">No file changes recorded.</div>'}
+    </div>`;
+}
+
 function changePage(delta) {
   const totalPages = Math.max(1, Math.ceil(filteredCommits.length / commitsPerPage));
   currentPage += delta;
@@ -0,0 +1,143 @@
+package gitsense
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TimeRange is the inclusive window an analytics request covers.
+// A zero From means the range is unbounded in the past.
+type TimeRange struct {
+	From time.Time
+	To   time.Time
+}
+
+// ValidateTimeRangeParams parses the optional from and to query parameters.
+// Each accepts a date (YYYY-MM-DD, UTC; from is the start of that day and to
+// the end of it), an RFC3339 timestamp, or a relative value counted back
+// from now: 90d, 12w, 6m or 1y. Without from the range covers all history;
+// without to it ends now. A to in the future is clamped to now.
+func ValidateTimeRangeParams(r *http.Request) (TimeRange, error) {
+	return ParseTimeRange(r.URL.Query().Get("from"), r.URL.Query().Get("to"))
+}
+
+// ParseTimeRange is ValidateTimeRangeParams for from/to values from
+// elsewhere, such as command-line flags
+func ParseTimeRange(fromValue, toValue string) (TimeRange, error) {
+	now := time.Now().UTC()
+	tr := TimeRange{To: now}
+
+	if value := strings.TrimSpace(fromValue); value != "" {
+		from, err := parseRangeBound(value, now, false)
+		if err != nil {
+			return tr, fmt.Errorf("invalid from: %v", err)
+		}
+		tr.From = from
+	}
+
+	if value := strings.TrimSpace(toValue); value != "" {
+		to, err := parseRangeBound(value, now, true)
+		if err != nil {
+			return tr, fmt.Errorf("invalid to: %v", err)
+		}
+		if to.Before(now) {
+			tr.To = to
+		}
+	}
+
+	if !tr.From.IsZero() && tr.From.After(tr.To) {
+		return tr, fmt.Errorf("from must not be after to")
+	}
+	return tr, nil
+}
+
+func parseRangeBound(value string, now time.Time, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+
+	if day, err := time.Parse("2006-01-02", value); err == nil {
+		if endOfDay {
+			return day.Add(24*time.Hour - time.Second), nil
+		}
+		return day, nil
+	}
+
+	if len(value) >= 2 {
+		n, err := strconv.Atoi(value[:len(value)-1])
+		if err == nil && n >= 0 && n <= MaxThresholdDays {
+			switch value[len(value)-1] {
+			case 'd':
+				return now.AddDate(0, 0, -n), nil
+			case 'w':
+				return now.AddDate(0, 0, -7*n), nil
+			case 'm':
+				return now.AddDate(0, -n, 0), nil
+			case 'y':
+				return now.AddDate(-n, 0, 0), nil
+			}
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("use YYYY-MM-DD, RFC3339 or a relative value like 90d, 12w, 6m, 1y")
+}
+
+// Bounded reports whether the range has a start
+func (tr TimeRange) Bounded() bool {
+	return !tr.From.IsZero()
+}
+
+// WithDefaultDays gives an unbounded range a start days before its end, for
+// handlers that have always looked at a recent window
+func (tr TimeRange) WithDefaultDays(days int) TimeRange {
+	if tr.From.IsZero() {
+		tr.From = tr.To.AddDate(0, 0, -days)
+	}
+	return tr
+}
+
+// Contains reports whether t falls within the range
+func (tr TimeRange) Contains(t time.Time) bool {
+	return !t.Before(tr.From) && !t.After(tr.To)
+}
+
+// ContainsTimestamp reports whether an RFC3339 timestamp falls within the range
+func (tr TimeRange) ContainsTimestamp(value string) bool {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return false
+	}
+	return tr.Contains(t)
+}
+
+// Bounds returns the range as RFC3339 strings for comparing against stored
+// timestamps in SQL; an unbounded start becomes the zero time
+func (tr TimeRange) Bounds() (string, string) {
+	return tr.From.UTC().Format(time.RFC3339), tr.To.UTC().Format(time.RFC3339)
+}
+
+// MarshalJSON echoes the effective range; from is null when unbounded
+func (tr TimeRange) MarshalJSON() ([]byte, error) {
+	var from interface{}
+	if !tr.From.IsZero() {
+		from = tr.From.Format(time.RFC3339)
+	}
+	return json.Marshal(map[string]interface{}{
+		"from": from,
+		"to":   tr.To.Format(time.RFC3339),
+	})
+}
+
+// Echo reports the effective range in response headers, for endpoints whose
+// body is a bare JSON array
+func (tr TimeRange) Echo(w http.ResponseWriter) {
+	if !tr.From.IsZero() {
+		w.Header().Set("X-Range-From", tr.From.Format(time.RFC3339))
+	}
+	w.Header().Set("X-Range-To", tr.To.Format(time.RFC3339))
+	w.Header().Set("Access-Control-Expose-Headers", "X-Range-From, X-Range-To")
+}
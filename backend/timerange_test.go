@@ -0,0 +1,99 @@
+package gitsense
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseTimeRange(t *testing.T) {
+	now := time.Now().UTC()
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02", s)
+		return d
+	}
+
+	tests := []struct {
+		name     string
+		from, to string
+		wantFrom time.Time // zero means unbounded
+		wantTo   time.Time // zero means now
+		wantErr  bool
+	}{
+		{"empty", "", "", time.Time{}, time.Time{}, false},
+		{"dates", "2024-01-01", "2024-01-31", day("2024-01-01"), day("2024-01-31").Add(24*time.Hour - time.Second), false},
+		{"rfc3339", "2024-01-01T12:00:00+02:00", "2024-01-02T00:00:00Z", time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC), day("2024-01-02"), false},
+		{"days", "90d", "", now.AddDate(0, 0, -90), time.Time{}, false},
+		{"weeks", "12w", "", now.AddDate(0, 0, -84), time.Time{}, false},
+		{"months", "6m", "", now.AddDate(0, -6, 0), time.Time{}, false},
+		{"years", "1y", "30d", now.AddDate(-1, 0, 0), now.AddDate(0, 0, -30), false},
+		{"whitespace", " 7d ", "", now.AddDate(0, 0, -7), time.Time{}, false},
+		{"future to clamps to now", "", "2999-01-01", time.Time{}, time.Time{}, false},
+		{"from after to", "2024-02-01", "2024-01-01", time.Time{}, time.Time{}, true},
+		{"unknown unit", "5x", "", time.Time{}, time.Time{}, true},
+		{"negative", "-5d", "", time.Time{}, time.Time{}, true},
+		{"too far back", "4000d", "", time.Time{}, time.Time{}, true},
+		{"garbage", "yesterday", "", time.Time{}, time.Time{}, true},
+		{"bad to", "", "2024-13-01", time.Time{}, time.Time{}, true},
+	}
+
+	near := func(a, b time.Time) bool {
+		d := a.Sub(b)
+		return d > -time.Minute && d < time.Minute
+	}
+
+	for _, tt := range tests {
+		tr, err := ParseTimeRange(tt.from, tt.to)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: err = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		if tt.wantFrom.IsZero() != tr.From.IsZero() || !near(tr.From, tt.wantFrom) {
+			t.Errorf("%s: from = %v, want %v", tt.name, tr.From, tt.wantFrom)
+		}
+		wantTo := tt.wantTo
+		if wantTo.IsZero() {
+			wantTo = now
+		}
+		if !near(tr.To, wantTo) {
+			t.Errorf("%s: to = %v, want %v", tt.name, tr.To, wantTo)
+		}
+	}
+}
+
+func TestTimeRangeContainsAndJSON(t *testing.T) {
+	tr := TimeRange{
+		From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		To:   time.Date(2024, 1, 31, 23, 59, 59, 0, time.UTC),
+	}
+	tests := []struct {
+		value string
+		want  bool
+	}{
+		{"2024-01-01T00:00:00Z", true},
+		{"2024-01-31T23:59:59Z", true},
+		{"2023-12-31T23:59:59Z", false},
+		{"2024-02-01T00:00:00Z", false},
+		{"2024-01-15T10:00:00+05:00", true},
+		{"not a date", false},
+	}
+	for _, tt := range tests {
+		if got := tr.ContainsTimestamp(tt.value); got != tt.want {
+			t.Errorf("ContainsTimestamp(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	unbounded, _ := json.Marshal(TimeRange{To: tr.To})
+	if string(unbounded) != `{"from":null,"to":"2024-01-31T23:59:59Z"}` {
+		t.Errorf("unbounded range JSON = %s", unbounded)
+	}
+	if got := (TimeRange{To: tr.To}).WithDefaultDays(30).From; !got.Equal(tr.To.AddDate(0, 0, -30)) {
+		t.Errorf("WithDefaultDays(30).From = %v", got)
+	}
+	if got := tr.WithDefaultDays(5).From; !got.Equal(tr.From) {
+		t.Errorf("WithDefaultDays(5) changed a bounded from to %v", got)
+	}
+}
@@ -493,9 +493,10 @@ function loadHistory(repo) {
         });
 }
 function loadCommits(repo) {
-    fetch(`${API_BASE_URL}/commits?repo=${repo}`)
+    fetch(`${API_BASE_URL}/commits?repo=${repo}&limit=5`)
       .then(res => res.json())
-      .then(commits => {
+      .then(page => {
+        const commits = page.commits;
         const list = document.getElementById("commitList");
         const section = document.getElementById("commitsSection");
 